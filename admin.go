@@ -0,0 +1,307 @@
+package logman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// importanceOverride is temporary minimum importance reverted to base by timer.
+type importanceOverride struct {
+	base  int
+	until time.Time
+	timer *time.Timer
+}
+
+// MinimumImportance returns minimum message importance Logger currently processes.
+func (l *Logger) MinimumImportance() int {
+	return l.state.Load().minimum
+}
+
+// SetMinimumImportance sets minimum message importance Logger processes
+// and cancels temporary override (see OverrideImportance).
+func (l *Logger) SetMinimumImportance(importance int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cancelOverride()
+	l.storeMinimum(importance)
+}
+
+// OverrideImportance sets minimum message importance for ttl. After ttl importance set
+// before first active override is restored. Override replaces previous active one.
+func (l *Logger) OverrideImportance(importance int, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("override ttl must be positive")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	base := l.state.Load().minimum
+	if l.override != nil {
+		base = l.override.base
+		l.override.timer.Stop()
+	}
+	ovr := &importanceOverride{base: base, until: time.Now().Add(ttl)}
+	ovr.timer = time.AfterFunc(ttl, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.override != ovr {
+			return
+		}
+		l.override = nil
+		l.storeMinimum(ovr.base)
+	})
+	l.override = ovr
+	l.storeMinimum(importance)
+	return nil
+}
+
+// CancelOverride restores minimum importance replaced by active override.
+func (l *Logger) CancelOverride() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.override == nil {
+		return
+	}
+	base := l.override.base
+	l.cancelOverride()
+	l.storeMinimum(base)
+}
+
+// cancelOverride forgets active override. l.mu must be held.
+func (l *Logger) cancelOverride() {
+	if l.override != nil {
+		l.override.timer.Stop()
+		l.override = nil
+	}
+}
+
+// storeMinimum publishes snapshot with new minimum importance. l.mu must be held.
+func (l *Logger) storeMinimum(importance int) {
+	if importance > ImportanceNONE {
+		importance = ImportanceNONE
+	}
+	if importance < ImportanceALL {
+		importance = ImportanceALL
+	}
+	next := *l.state.Load()
+	next.minimum = importance
+	l.state.Store(&next)
+}
+
+// SetLevelEnabled enables or disables level. Messages of disabled level are skipped
+// as if their importance was below minimum.
+func (l *Logger) SetLevelEnabled(level string, enabled bool) error {
+	return l.updateLevels(func(levels levelTable) error {
+		if _, ok := levels[level]; !ok {
+			return fmt.Errorf("logman has no level '%v'", level)
+		}
+		levels[level].disabled = !enabled
+		return nil
+	})
+}
+
+// MinimumImportance returns minimum message importance default Logger processes.
+func MinimumImportance() int {
+	return Default().MinimumImportance()
+}
+
+// SetMinimumImportance sets minimum message importance default Logger processes.
+func SetMinimumImportance(importance int) {
+	Default().SetMinimumImportance(importance)
+}
+
+// OverrideImportance sets minimum message importance of default Logger for ttl.
+func OverrideImportance(importance int, ttl time.Duration) error {
+	return Default().OverrideImportance(importance, ttl)
+}
+
+// SetLevelEnabled enables or disables level of default Logger.
+func SetLevelEnabled(level string, enabled bool) error {
+	return Default().SetLevelEnabled(level, enabled)
+}
+
+// AdminStatus is state of Logger reported by admin handler.
+type AdminStatus struct {
+	Minimum   int               `json:"minimum"`
+	Override  *OverrideStatus   `json:"override,omitempty"`
+	Verbosity string            `json:"verbosity"`
+	Levels    []AdminLevelState `json:"levels"`
+}
+
+// OverrideStatus describes active temporary override.
+type OverrideStatus struct {
+	Importance int       `json:"importance"`
+	Base       int       `json:"base"`
+	Expires    time.Time `json:"expires"`
+}
+
+// AdminLevelState describes level and its writers.
+type AdminLevelState struct {
+	Name       string   `json:"name"`
+	Tag        string   `json:"tag"`
+	Importance int      `json:"importance"`
+	Enabled    bool     `json:"enabled"`
+	Writers    []string `json:"writers"`
+}
+
+// Status returns current minimum importance, override, verbosity rules and levels of Logger.
+// Levels are sorted by importance (most important first).
+func (l *Logger) Status() AdminStatus {
+	l.mu.Lock()
+	snap := l.state.Load()
+	status := AdminStatus{Minimum: snap.minimum}
+	if snap.rules != nil {
+		status.Verbosity = snap.rules.spec
+	}
+	if l.override != nil {
+		status.Override = &OverrideStatus{Importance: snap.minimum, Base: l.override.base, Expires: l.override.until}
+	}
+	l.mu.Unlock()
+	for _, lvl := range snap.levels {
+		state := AdminLevelState{Name: lvl.name, Tag: lvl.tag, Importance: lvl.importance, Enabled: !lvl.disabled, Writers: []string{}}
+		for key := range lvl.writerFormatterMap {
+			state.Writers = append(state.Writers, key)
+		}
+		sort.Strings(state.Writers)
+		status.Levels = append(status.Levels, state)
+	}
+	sort.Slice(status.Levels, func(i, j int) bool {
+		if status.Levels[i].Importance != status.Levels[j].Importance {
+			return status.Levels[i].Importance > status.Levels[j].Importance
+		}
+		return status.Levels[i].Name < status.Levels[j].Name
+	})
+	return status
+}
+
+// adminRequest is body of POST request to admin handler.
+// Importance is level name or number.
+type adminRequest struct {
+	Minimum   json.RawMessage `json:"minimum"`
+	Levels    map[string]bool `json:"levels"`
+	Verbosity *string         `json:"verbosity"`
+	Override  *struct {
+		Importance json.RawMessage `json:"importance"`
+		TTL        string          `json:"ttl"`
+	} `json:"override"`
+}
+
+// NewAdminHandler returns http.Handler controlling l (default Logger if nil).
+//
+//	GET    - reports AdminStatus as JSON.
+//	POST   - applies JSON body and reports new status:
+//	         {"minimum": "debug", "levels": {"trace": false}, "verbosity": "db=trace",
+//	          "override": {"importance": "trace", "ttl": "5m"}}
+//	         Importance is level name or number. Request is validated before any change is made.
+//	DELETE - cancels temporary override.
+func NewAdminHandler(l *Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lgr := l
+		if lgr == nil {
+			lgr = Default()
+		}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			if err := lgr.applyAdminRequest(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		case http.MethodDelete:
+			lgr.CancelOverride()
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(lgr.Status())
+	})
+}
+
+func (l *Logger) applyAdminRequest(r *http.Request) error {
+	req := adminRequest{}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return fmt.Errorf("bad request body: %v", err)
+	}
+	known := l.levels().importances()
+	problems := []error{}
+	minimum, setMinimum := 0, req.Minimum != nil
+	if setMinimum {
+		imp, err := rawImportance(req.Minimum, known)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("minimum: %v", err))
+		}
+		minimum = imp
+	}
+	levels := make([]string, 0, len(req.Levels))
+	for level := range req.Levels {
+		if _, ok := known[level]; !ok {
+			problems = append(problems, fmt.Errorf("levels: logman has no level '%v'", level))
+		}
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	var rules *verbosityRules
+	if req.Verbosity != nil {
+		parsed, err := parseVerbosity(*req.Verbosity, known)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("verbosity: %v", err))
+		}
+		rules = parsed
+	}
+	override, ttl := 0, time.Duration(0)
+	if req.Override != nil {
+		imp, err := rawImportance(req.Override.Importance, known)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("override.importance: %v", err))
+		}
+		override = imp
+		ttl, err = time.ParseDuration(req.Override.TTL)
+		if err != nil || ttl <= 0 {
+			problems = append(problems, fmt.Errorf("override.ttl: bad duration '%v'", req.Override.TTL))
+		}
+	}
+	if err := joinErrors("invalid request", problems...); err != nil {
+		return err
+	}
+
+	if setMinimum {
+		l.SetMinimumImportance(minimum)
+	}
+	for _, level := range levels {
+		if err := l.SetLevelEnabled(level, req.Levels[level]); err != nil {
+			return err
+		}
+	}
+	if req.Verbosity != nil {
+		l.storeRules(rules)
+	}
+	if req.Override != nil {
+		return l.OverrideImportance(override, ttl)
+	}
+	return nil
+}
+
+// rawImportance parses JSON number or string with level name or number.
+func rawImportance(raw json.RawMessage, known map[string]int) (int, error) {
+	if raw == nil {
+		return 0, fmt.Errorf("importance is not set")
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return 0, err
+	}
+	switch v := value.(type) {
+	case float64:
+		return parseImportance(strconv.FormatFloat(v, 'f', -1, 64), known)
+	case string:
+		return parseImportance(v, known)
+	}
+	return 0, fmt.Errorf("expected level name or number, have %s", raw)
+}
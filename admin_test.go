@@ -0,0 +1,97 @@
+package logman
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminHandler(t *testing.T) {
+	buf := &lockedBuffer{}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(
+			NewLoggingLevel(INFO),
+			NewLoggingLevel(DEBUG, LevelImportance(ImportanceDEBUG)),
+			NewLoggingLevel(TRACE, LevelImportance(ImportanceTRACE)),
+		),
+		WithWriterNamed("buffer", buf, formatter),
+		WithAppLogLevelImportance(ImportanceINFO),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewAdminHandler(lgr)
+	call := func(method, body string) (int, AdminStatus) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/logman", strings.NewReader(body)))
+		status := AdminStatus{}
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec.Code, status
+	}
+
+	levelState := func(status AdminStatus, name string) AdminLevelState {
+		for _, state := range status.Levels {
+			if state.Name == name {
+				return state
+			}
+		}
+		t.Fatalf("no level %v in status", name)
+		return AdminLevelState{}
+	}
+
+	code, status := call(http.MethodGet, "")
+	if code != http.StatusOK || status.Minimum != ImportanceINFO || status.Levels[0].Name != FATAL {
+		t.Fatalf("unexpected status %v %+v", code, status)
+	}
+	if info := levelState(status, INFO); strings.Join(info.Writers, ",") != "buffer" || !info.Enabled {
+		t.Errorf("unexpected level state %+v", info)
+	}
+
+	code, _ = call(http.MethodPost, `{"minimum": "debug", "levels": {"nope": false}, "override": {"importance": 5, "ttl": "x"}}`)
+	if code != http.StatusBadRequest || lgr.MinimumImportance() != ImportanceINFO {
+		t.Errorf("invalid request must change nothing: %v, minimum %v", code, lgr.MinimumImportance())
+	}
+
+	code, status = call(http.MethodPost, `{"minimum": "debug", "levels": {"info": false}}`)
+	if code != http.StatusOK || status.Minimum != ImportanceDEBUG || levelState(status, INFO).Enabled {
+		t.Fatalf("unexpected status %v %+v", code, status)
+	}
+	lgr.Info("skipped")
+	lgr.Debug(NewMessage("debug"))
+	lgr.Trace(NewMessage("skipped"))
+
+	code, status = call(http.MethodPost, `{"override": {"importance": "trace", "ttl": "50ms"}}`)
+	if code != http.StatusOK || status.Override == nil || status.Minimum != ImportanceTRACE || status.Override.Base != ImportanceDEBUG {
+		t.Fatalf("unexpected status %v %+v", code, status)
+	}
+	lgr.Trace(NewMessage("trace"))
+	deadline := time.Now().Add(5 * time.Second)
+	for lgr.MinimumImportance() != ImportanceDEBUG {
+		if time.Now().After(deadline) {
+			t.Fatal("override was not reverted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	lgr.Trace(NewMessage("skipped"))
+	if buf.String() != "debug \ntrace \n" {
+		t.Errorf("unexpected log %q", buf.String())
+	}
+
+	call(http.MethodPost, `{"override": {"importance": 1, "ttl": "1h"}}`)
+	code, status = call(http.MethodDelete, "")
+	if code != http.StatusOK || status.Override != nil || status.Minimum != ImportanceDEBUG {
+		t.Errorf("unexpected status after cancel %v %+v", code, status)
+	}
+	if code, _ := call(http.MethodPut, ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected %v, have %v", http.StatusMethodNotAllowed, code)
+	}
+}
@@ -0,0 +1,267 @@
+package logman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+var errAsyncClosed = errors.New("async pipeline is closed")
+
+type overflowMode int
+
+const (
+	overflowBlock overflowMode = iota
+	overflowDropNewest
+	overflowDropOldest
+	overflowDropBelow
+)
+
+// OverflowPolicy decides what happens with message when async queue is full.
+type OverflowPolicy struct {
+	mode       overflowMode
+	importance int
+}
+
+var (
+	// OverflowBlock makes caller wait until queue has free space.
+	OverflowBlock = OverflowPolicy{mode: overflowBlock}
+	// OverflowDropNewest drops message that does not fit into queue.
+	OverflowDropNewest = OverflowPolicy{mode: overflowDropNewest}
+	// OverflowDropOldest drops oldest queued message to make space for new one.
+	OverflowDropOldest = OverflowPolicy{mode: overflowDropOldest}
+)
+
+// OverflowDropBelow drops message that does not fit into queue if its importance
+// is below provided. More important messages wait for free space.
+func OverflowDropBelow(importance int) OverflowPolicy {
+	return OverflowPolicy{mode: overflowDropBelow, importance: importance}
+}
+
+type asyncSettings struct {
+	bufferSize int
+	workers    int
+	policy     OverflowPolicy
+}
+
+// WithAsync - makes Logger write messages in background.
+// Messages are put into queue of bufferSize and written by workers goroutines
+// (more than one worker does not preserve order of messages). When queue is full
+// policy is applied. Messages of levels that exit program are written synchronously
+// after queue is flushed.
+// Use Flush or Close to guarantee delivery before program ends.
+func WithAsync(bufferSize, workers int, policy OverflowPolicy) LogmanOptions {
+	return func(o *options) {
+		if bufferSize < 1 {
+			bufferSize = 1
+		}
+		if workers < 1 {
+			workers = 1
+		}
+		o.async = &asyncSettings{
+			bufferSize: bufferSize,
+			workers:    workers,
+			policy:     policy,
+		}
+	}
+}
+
+// AsyncStats holds counters of async pipeline.
+type AsyncStats struct {
+	Enqueued uint64
+	Written  uint64
+	Dropped  uint64
+	Failed   uint64
+}
+
+type asyncPipeline struct {
+	logger   *Logger
+	settings asyncSettings
+	queue    chan *delivery
+
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+
+	pendingMu sync.Mutex
+	pending   int
+	drained   chan struct{}
+
+	enqueued atomic.Uint64
+	written  atomic.Uint64
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+}
+
+func newAsyncPipeline(l *Logger, settings asyncSettings) *asyncPipeline {
+	ap := asyncPipeline{
+		logger:   l,
+		settings: settings,
+		queue:    make(chan *delivery, settings.bufferSize),
+		drained:  make(chan struct{}),
+	}
+	for i := 0; i < settings.workers; i++ {
+		ap.workers.Add(1)
+		go ap.work()
+	}
+	return &ap
+}
+
+func (ap *asyncPipeline) work() {
+	defer ap.workers.Done()
+	for dlv := range ap.queue {
+		errs := ap.logger.deliver(dlv)
+		dlv.release()
+		dlv.recycle()
+		switch len(errs) {
+		case 0:
+			ap.written.Add(1)
+		default:
+			ap.failed.Add(1)
+			fmt.Fprintf(os.Stderr, "logman: %v\n", joinErrors("async writing failed", errs...))
+		}
+		ap.done()
+	}
+}
+
+func (ap *asyncPipeline) add() {
+	ap.pendingMu.Lock()
+	ap.pending++
+	ap.pendingMu.Unlock()
+}
+
+func (ap *asyncPipeline) done() {
+	ap.pendingMu.Lock()
+	ap.pending--
+	if ap.pending == 0 {
+		close(ap.drained)
+		ap.drained = make(chan struct{})
+	}
+	ap.pendingMu.Unlock()
+}
+
+// enqueue puts message into queue applying overflow policy.
+// Dropped message is counted and not reported as error.
+func (ap *asyncPipeline) enqueue(dlv *delivery) error {
+	if len(dlv.levels) == 0 {
+		return nil
+	}
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+	if ap.closed {
+		return errAsyncClosed
+	}
+	ap.add()
+	select {
+	case ap.queue <- dlv:
+		ap.enqueued.Add(1)
+		return nil
+	default:
+	}
+	switch ap.settings.policy.mode {
+	case overflowDropNewest:
+		ap.drop(dlv)
+		return nil
+	case overflowDropBelow:
+		if dlv.importance < ap.settings.policy.importance {
+			ap.drop(dlv)
+			return nil
+		}
+	case overflowDropOldest:
+		for {
+			select {
+			case ap.queue <- dlv:
+				ap.enqueued.Add(1)
+				return nil
+			default:
+			}
+			select {
+			case oldest := <-ap.queue:
+				ap.drop(oldest)
+			default:
+			}
+		}
+	}
+	ap.queue <- dlv
+	ap.enqueued.Add(1)
+	return nil
+}
+
+func (ap *asyncPipeline) drop(dlv *delivery) {
+	dlv.release()
+	dlv.recycle()
+	ap.dropped.Add(1)
+	ap.done()
+}
+
+// flush waits until all queued messages are written.
+func (ap *asyncPipeline) flush(ctx context.Context) error {
+	ap.pendingMu.Lock()
+	if ap.pending == 0 {
+		ap.pendingMu.Unlock()
+		return nil
+	}
+	drained := ap.drained
+	ap.pendingMu.Unlock()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close stops accepting messages and waits for workers to write queued ones.
+func (ap *asyncPipeline) close(ctx context.Context) error {
+	ap.mu.Lock()
+	if !ap.closed {
+		ap.closed = true
+		close(ap.queue)
+	}
+	ap.mu.Unlock()
+	stopped := make(chan struct{})
+	go func() {
+		ap.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (ap *asyncPipeline) stats() AsyncStats {
+	return AsyncStats{
+		Enqueued: ap.enqueued.Load(),
+		Written:  ap.written.Load(),
+		Dropped:  ap.dropped.Load(),
+		Failed:   ap.failed.Load(),
+	}
+}
+
+// Flush waits until all messages queued by async Logger are written or ctx is done.
+// It does nothing for synchronous Logger.
+func (l *Logger) Flush(ctx context.Context) error {
+	if l.async == nil {
+		return nil
+	}
+	return l.async.flush(ctx)
+}
+
+// AsyncStats returns counters of async pipeline. All counters are zero for synchronous Logger.
+func (l *Logger) AsyncStats() AsyncStats {
+	if l.async == nil {
+		return AsyncStats{}
+	}
+	return l.async.stats()
+}
+
+// Flush waits until all messages queued by default Logger are written or ctx is done.
+func Flush(ctx context.Context) error {
+	return Default().Flush(ctx)
+}
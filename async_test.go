@@ -0,0 +1,141 @@
+package logman
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateWriter blocks every Write until released.
+type gateWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (g *gateWriter) Write(p []byte) (int, error) {
+	g.entered <- struct{}{}
+	<-g.release
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(p)
+}
+
+func (g *gateWriter) lines() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return strings.Fields(strings.ReplaceAll(g.buf.String(), " \n", "\n"))
+}
+
+func newAsyncTestLogger(t *testing.T, w *gateWriter, policy OverflowPolicy) *Logger {
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(WARN, LevelImportance(ImportanceWARN))),
+		WithWriterNamed("gate", w, formatter),
+		WithAsync(2, 1, policy),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lgr
+}
+
+func TestAsyncOverflowPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		policy   OverflowPolicy
+		expected []string
+		dropped  uint64
+	}{
+		{OverflowDropNewest, []string{"m0", "m1", "m2"}, 5},
+		{OverflowDropOldest, []string{"m0", "m6", "m7"}, 5},
+		{OverflowDropBelow(ImportanceWARN), []string{"m0", "m1", "m2", "w0"}, 5},
+	} {
+		gate := newGateWriter()
+		lgr := newAsyncTestLogger(t, gate, tc.policy)
+		lgr.Info("m0")
+		<-gate.entered
+		for i := 1; i < 8; i++ {
+			lgr.Info("m%v", i)
+		}
+		if tc.policy.mode == overflowDropBelow {
+			sent := make(chan struct{})
+			go func() {
+				lgr.Warn("w0")
+				close(sent)
+			}()
+			select {
+			case <-sent:
+				t.Errorf("important message must wait for free space")
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+		close(gate.release)
+		if err := lgr.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		stats := lgr.AsyncStats()
+		if stats.Dropped != tc.dropped {
+			t.Errorf("policy %v: expected %v dropped, have %+v", tc.policy, tc.dropped, stats)
+		}
+		if fmt.Sprint(gate.lines()) != fmt.Sprint(tc.expected) {
+			t.Errorf("policy %v: expected %v written, have %v", tc.policy, tc.expected, gate.lines())
+		}
+	}
+}
+
+func TestAsyncFlush(t *testing.T) {
+	buf := &lockedBuffer{}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO)),
+		WithWriterNamed("buffer", buf, formatter),
+		WithAsync(16, 1, OverflowBlock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		lgr.Info("%v", i)
+	}
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 1000 {
+		t.Errorf("expected 1000 lines after Flush, have %v", lines)
+	}
+	if stats := lgr.AsyncStats(); stats.Written != 1000 || stats.Dropped != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := lgr.Info("after close"); err == nil {
+		t.Errorf("expected error after Close")
+	}
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,98 @@
+package logman
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func benchLogger(b *testing.B, formatter *formatterExpanded, opts ...LogmanOptions) *Logger {
+	b.Helper()
+	opts = append([]LogmanOptions{
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(DEBUG, LevelImportance(ImportanceDEBUG)), NewLoggingLevel(ERROR, LevelImportance(ImportanceERROR))),
+		WithWriterNamed("discard", io.Discard, formatter),
+	}, opts...)
+	lgr, err := New(opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	lgr.SetMinimumImportance(ImportanceINFO)
+	return lgr
+}
+
+func textFormatter() *formatterExpanded {
+	return NewFormatter(WithRequestedFields(Request_ShortTime))
+}
+
+func jsonFormatter() *formatterExpanded {
+	return NewFormatter(WithCustomFunc("json", stdJSON), WithRequestedFields([]string{"json"}))
+}
+
+func BenchmarkDisabledLevel(b *testing.B) {
+	lgr := benchLogger(b, textFormatter())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lgr.Debugf("request %v took %v", "GET /", 42)
+	}
+}
+
+func BenchmarkDisabledMessage(b *testing.B) {
+	lgr := benchLogger(b, textFormatter())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lgr.ProcessMessage(NewMessage("request %v took %v", "GET /", 42), DEBUG)
+	}
+}
+
+func BenchmarkInfoText(b *testing.B) {
+	lgr := benchLogger(b, textFormatter())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lgr.Info("request %v took %v", "GET /", 42)
+	}
+}
+
+func BenchmarkInfoJSONFields(b *testing.B) {
+	lgr := benchLogger(b, jsonFormatter()).With(String("service", "api"), Int("port", 8080))
+	err := errors.New("timeout")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lgr.ProcessMessage(NewMessage("request %v failed", "GET /").WithFields(Duration("took", time.Second), Err(err)), ERROR)
+	}
+}
+
+func BenchmarkInfoParallel(b *testing.B) {
+	lgr := benchLogger(b, textFormatter())
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			lgr.Info("request %v took %v", "GET /", 42)
+		}
+	})
+}
+
+func TestHotPathAllocations(t *testing.T) {
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(DEBUG, LevelImportance(ImportanceDEBUG))),
+		WithWriterNamed("discard", io.Discard, textFormatter()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgr.SetMinimumImportance(ImportanceINFO)
+	if n := testing.AllocsPerRun(100, func() { lgr.Debugf("request %v took %v", "GET /", 42) }); n != 0 {
+		t.Errorf("disabled level: expected no allocations, got %v", n)
+	}
+	if raceEnabled {
+		return
+	}
+	if n := testing.AllocsPerRun(100, func() { lgr.Info("request %v took %v", "GET /", 42) }); n > 5 {
+		t.Errorf("enabled level: expected at most 5 allocations, got %v", n)
+	}
+}
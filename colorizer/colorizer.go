@@ -3,7 +3,9 @@ package colorizer
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gookit/color"
 )
@@ -14,7 +16,17 @@ func (c *colorSchema) ColorizeByType(arg interface{}) string {
 	return colorizeByType(c, arg)
 }
 
+// Typed is value which knows kind it is colored as (like "struct" or "slice").
+// Typed values are colorized as a whole without reflection.
+type Typed interface {
+	ColorKind() string
+	String() string
+}
+
 func colorizeByType(c *colorSchema, arg interface{}) string {
+	if kind, text, ok := scalar(arg); ok {
+		return color.S256(colorToField(c, kind, FG_KEY), colorToField(c, kind, BG_KEY)).Sprint(text)
+	}
 	s := ""
 	argVal := reflect.ValueOf(arg)
 	flds := constructFields(argVal)
@@ -56,6 +68,80 @@ func colorizeByKeys(c *colorSchema, arg interface{}, keys ...ColorKey) string {
 	return s
 }
 
+// Kind returns name of kind argument is colored as, like "int", "string" or "struct".
+func Kind(arg interface{}) string {
+	if kind, _, ok := scalar(arg); ok {
+		return kind
+	}
+	argVal := reflect.ValueOf(arg)
+	switch argVal.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		if argVal.IsNil() {
+			return "nil"
+		}
+	}
+	return argVal.Kind().String()
+}
+
+// scalar returns kind and text of values colored without reflection.
+func scalar(arg interface{}) (string, string, bool) {
+	switch v := arg.(type) {
+	case nil:
+		return "nil", "<nil>", true
+	case string:
+		return "string", v, true
+	case bool:
+		return "bool", strconv.FormatBool(v), true
+	case int:
+		return "int", strconv.Itoa(v), true
+	case int8:
+		return "int8", strconv.FormatInt(int64(v), 10), true
+	case int16:
+		return "int16", strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return "int32", strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return "int64", strconv.FormatInt(v, 10), true
+	case uint:
+		return "uint", strconv.FormatUint(uint64(v), 10), true
+	case uint8:
+		return "uint8", strconv.FormatUint(uint64(v), 10), true
+	case uint16:
+		return "uint16", strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return "uint32", strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return "uint64", strconv.FormatUint(v, 10), true
+	case float32:
+		return "float32", strconv.FormatFloat(float64(v), 'g', -1, 32), true
+	case float64:
+		return "float64", strconv.FormatFloat(v, 'g', -1, 64), true
+	case time.Duration:
+		return "duration", v.String(), true
+	case time.Time:
+		return "time", v.String(), true
+	case Typed:
+		return v.ColorKind(), methodText(arg, v.String, "String"), true
+	case error:
+		return "error", methodText(arg, v.Error, "Error"), true
+	}
+	return "", "", false
+}
+
+// methodText calls String or Error method of arg recovering from panics as fmt does.
+func methodText(arg interface{}, method func() string, name string) (text string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if v := reflect.ValueOf(arg); v.Kind() == reflect.Ptr && v.IsNil() {
+				text = "<nil>"
+				return
+			}
+			text = fmt.Sprintf("%%!v(PANIC=%v method: %v)", name, r)
+		}
+	}()
+	return method()
+}
+
 func approveForcedKeys(forcedKeys ...ColorKey) error {
 	if len(forcedKeys) != 2 {
 		return fmt.Errorf("expect 2 keys to be valid")
@@ -46,3 +46,20 @@ func TestColorizer(t *testing.T) {
 		fmt.Printf("%v' (%v %v) of type '%v'\n", s, cl.color256[fgKey(argType)], cl.color256[bgKey(argType)], argType)
 	}
 }
+
+func TestKind(t *testing.T) {
+	var nilMap map[string]int
+	for arg, want := range map[interface{}]string{
+		nil: "nil", 5: "int", "s": "string", 2.5: "float64", struct{}{}: "struct", &struct{}{}: "ptr",
+	} {
+		if got := Kind(arg); got != want {
+			t.Errorf("Kind(%#v): expected %v, got %v", arg, want, got)
+		}
+	}
+	if got := Kind([]int{1}); got != "slice" {
+		t.Errorf("expected slice, got %v", got)
+	}
+	if got := Kind(nilMap); got != "nil" {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
@@ -56,6 +56,8 @@ func defaultColorKey() map[ColorKey]uint8 {
 	colMap[fgKey("float32")] = 9
 	colMap[fgKey("float64")] = 9
 	colMap[fgKey("bool")] = 12
+	colMap[fgKey("duration")] = 120
+	colMap[fgKey("time")] = 120
 
 	colMap[fgKey("struct")] = 221
 	colMap[fgKey("slice")] = 14 //248
@@ -0,0 +1,183 @@
+package logman
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Run with: go test -race
+func TestConcurrentLoggingAndReconfiguration(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.log")
+	pathB := filepath.Join(dir, "b.log")
+	for _, path := range []string{pathA, pathB} {
+		if err := os.WriteFile(path, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(
+			NewLoggingLevel(INFO, WithWriter(pathA, formatter)),
+			NewLoggingLevel(WARN, LevelImportance(ImportanceWARN), WithWriter(pathA, formatter)),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				lgr.Info("worker %v message %v", g, i)
+				lgr.Warn("worker %v warning %v", g, i)
+				lgr.ProcessMessage(NewMessage("worker %v processed %v", g, i), INFO, WARN)
+			}
+		}(g)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			if err := lgr.SetLevelWriterFormatter(INFO, pathB, formatter); err != nil {
+				t.Error(err)
+			}
+			if err := lgr.RemovetWriter(INFO, pathB); err != nil {
+				t.Error(err)
+			}
+			if err := lgr.ResetWriters(WARN); err != nil {
+				t.Error(err)
+			}
+			if err := lgr.SetLevelWriterFormatter(WARN, pathA, formatter); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	wg.Wait()
+
+	for _, path := range []string{pathA, pathB} {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "worker ") || len(strings.Fields(line)) != 4 {
+				t.Errorf("%v: interleaved line %q", filepath.Base(path), line)
+			}
+		}
+		f.Close()
+	}
+}
+
+func TestConcurrentSetup(t *testing.T) {
+	original := Default()
+	defer SetDefault(original)
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	path := filepath.Join(t.TempDir(), "setup.log")
+	if err := os.WriteFile(path, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := Setup(WithLogLevels(NewLoggingLevel(INFO, WithWriter(path, formatter)))); err != nil {
+		t.Fatal(err)
+	}
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				Info("message %v", i)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				Setup(WithLogLevels(NewLoggingLevel(INFO, WithWriter(path, formatter))))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+type closeCounter struct {
+	mu     sync.Mutex
+	closed int
+}
+
+func (c *closeCounter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (c *closeCounter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed++
+	return nil
+}
+
+func TestSetupClosesPrevious(t *testing.T) {
+	original := Default()
+	defer SetDefault(original)
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	first, second := &closeCounter{}, &closeCounter{}
+	if err := Setup(WithWriterNamed("first", first, formatter)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Setup(WithWriterNamed("second", second, formatter)); err != nil {
+		t.Fatal(err)
+	}
+	if first.closed != 1 {
+		t.Errorf("writer of replaced default logger is not closed")
+	}
+	own, err := New(WithWriterNamed("own", second, formatter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetDefault(own)
+	if err := Setup(); err != nil {
+		t.Fatal(err)
+	}
+	if second.closed != 0 {
+		t.Errorf("logger set by SetDefault is closed by Setup")
+	}
+}
+
+func TestSetupKeepsDerivedLoggers(t *testing.T) {
+	original := Default()
+	defer SetDefault(original)
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	first, second := &closeTracker{}, &closeTracker{}
+	if err := Setup(WithWriterNamed("first", first, formatter)); err != nil {
+		t.Fatal(err)
+	}
+	child := With(NewField("component", "billing"))
+	ctx := NewContext(context.Background(), Default())
+	handler := NewSlogHandler(Default())
+	if err := Setup(WithWriterNamed("second", second, formatter)); err != nil {
+		t.Fatal(err)
+	}
+	if !first.closed {
+		t.Errorf("writer of replaced default logger is not closed")
+	}
+	if err := child.Info("child"); err != nil {
+		t.Errorf("child logger created before Setup fails: %v", err)
+	}
+	if err := FromContext(ctx).Info("from context"); err != nil {
+		t.Errorf("logger of context fails: %v", err)
+	}
+	slog.New(handler).Info("slog")
+	if second.String() != "child \nfrom context \nslog \n" {
+		t.Errorf("messages of derived loggers are not written by new default: %q", second.String())
+	}
+}
@@ -0,0 +1,801 @@
+package logman
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Galdoba/logman/colorizer"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables overriding configuration loaded by LoadConfig.
+const (
+	EnvConfig     = "LOGMAN_CONFIG"    // path to config file used when LoadConfig receives empty path
+	EnvImportance = "LOGMAN_LEVEL"     // minimum importance: level name or number
+	EnvAppName    = "LOGMAN_APP_NAME"  // application name
+	EnvColors     = "LOGMAN_COLORS"    // color scheme: "default" or "none"
+	EnvVerbosity  = "LOGMAN_VERBOSITY" // verbosity rules (see SetVerbosity)
+)
+
+// Config is declarative description of Logger. It can be loaded from JSON or YAML:
+//
+//	app_name: scribe
+//	importance: info            # level name or number
+//	verbosity: github.com/acme/app/db/...=trace,component:billing=debug
+//	colors:
+//	  scheme: default           # "default" or "none"
+//	  fg: {error: 196}          # color256 overrides by key
+//	levels:
+//	  - {name: audit, tag: AUDIT, importance: 60, caller_info: true, exit: false}
+//	  - name: error
+//	    importance: error
+//	    sampling: {first: 10, interval: 1s, thereafter: 100, rate: 50, burst: 100, summary: 1m}
+//	writers:
+//	  - key: StdErr             # StdErr, StdOut or path (directory if ends with separator)
+//	    fields: short_time      # preset or list of fields
+//	    levels: [info, warn]    # all levels if omitted
+//	    importance: info        # writer importance floor
+//	    match: {component: billing}
+//	  - key: /var/log/app.log
+//	    format: json
+//	    rotation: {max_size: 10485760, period: 24h, keep: 7, max_age: 168h, compress: true}
+type Config struct {
+	AppName    string
+	Importance string
+	Verbosity  string
+	Colors     *ColorConfig
+	Levels     []LevelConfig
+	Writers    []WriterConfig
+}
+
+// ColorConfig describes color scheme of console writers.
+type ColorConfig struct {
+	Scheme string
+	FG     map[string]uint8
+	BG     map[string]uint8
+}
+
+// LevelConfig describes logging level. Level with standard name overrides default one.
+type LevelConfig struct {
+	Name       string
+	Tag        string
+	Importance string
+	CallerInfo bool
+	Exit       bool
+	Sampling   *SamplingConfig
+}
+
+// SamplingConfig describes sampler of level (see WithSampling).
+type SamplingConfig struct {
+	First      int
+	Interval   time.Duration
+	Thereafter int
+	Rate       float64
+	Burst      int
+	Summary    time.Duration
+}
+
+// WriterConfig describes writer attached to levels.
+type WriterConfig struct {
+	Key        string
+	Fields     []string
+	Format     string
+	Color      *bool
+	Levels     []string
+	Rotation   *RotationConfig
+	Importance string
+	Match      map[string]string
+}
+
+// RotationConfig describes rotation of file writer.
+type RotationConfig struct {
+	MaxSize  int64
+	Period   time.Duration
+	Keep     int
+	MaxAge   time.Duration
+	Compress bool
+}
+
+// ConfigError lists every problem found in configuration.
+type ConfigError struct {
+	Problems []string
+}
+
+func (ce *ConfigError) Error() string {
+	return "invalid logman config:\n" + strings.Join(ce.Problems, "\n")
+}
+
+var fieldPresets = map[string][]string{
+	"message_only": Request_MessageOnly,
+	"short_time":   Request_ShortTime,
+	"short_since":  Request_ShortSince,
+	"short_report": Request_ShortReport,
+	"medium":       Request_Medium,
+	"full":         Request_Full,
+	"with_error":   Request_Error,
+}
+
+var importanceNames = map[string]int{
+	"none": ImportanceNONE,
+	FATAL:  ImportanceFATAL,
+	ERROR:  ImportanceERROR,
+	WARN:   ImportanceWARN,
+	INFO:   ImportanceINFO,
+	DEBUG:  ImportanceDEBUG,
+	TRACE:  ImportanceTRACE,
+	PING:   ImportancePING,
+	"all":  ImportanceALL,
+}
+
+// ParseConfig decodes configuration from data. Format is "json", "yaml" or "yml".
+// Unknown keys and values of wrong type are reported all at once as *ConfigError.
+func ParseConfig(data []byte, format string) (*Config, error) {
+	var tree interface{}
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "json":
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("failed to parse json config: %v", err)
+		}
+	case "yaml", "yml":
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("failed to parse yaml config: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown config format '%v'", format)
+	}
+	d := configDecoder{}
+	cfg := d.config(tree)
+	if len(d.problems) > 0 {
+		return nil, &ConfigError{Problems: d.problems}
+	}
+	return cfg, nil
+}
+
+// LoadConfig reads configuration file (format is taken from extension) and applies
+// environment overrides (see EnvImportance, EnvAppName, EnvColors, EnvVerbosity).
+// If path is empty, path from EnvConfig is used; if it is empty too, configuration
+// is made from environment only.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(EnvConfig)
+	}
+	cfg := &Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %v", err)
+		}
+		cfg, err = ParseConfig(data, filepath.Ext(path))
+		if err != nil {
+			return nil, err
+		}
+	}
+	cfg.ApplyEnv()
+	return cfg, nil
+}
+
+// ApplyEnv overrides configuration with environment variables which are set.
+func (c *Config) ApplyEnv() {
+	if val, ok := os.LookupEnv(EnvImportance); ok {
+		c.Importance = val
+	}
+	if val, ok := os.LookupEnv(EnvAppName); ok {
+		c.AppName = val
+	}
+	if val, ok := os.LookupEnv(EnvVerbosity); ok {
+		c.Verbosity = val
+	}
+	if val, ok := os.LookupEnv(EnvColors); ok {
+		if c.Colors == nil {
+			c.Colors = &ColorConfig{}
+		}
+		c.Colors.Scheme = val
+	}
+}
+
+// NewFromConfig creates Logger from configuration file (see LoadConfig).
+func NewFromConfig(path string) (*Logger, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	return New(opts...)
+}
+
+// SetupFromConfig sets default Logger from configuration file (see LoadConfig).
+// Replaced default Logger is closed the same way Setup does it.
+func SetupFromConfig(path string) error {
+	l, err := NewFromConfig(path)
+	if err != nil {
+		return err
+	}
+	return replaceDefault(l)
+}
+
+// Options validates configuration and converts it to options for New and Setup.
+// Every problem found is reported in *ConfigError.
+func (c *Config) Options() ([]LogmanOptions, error) {
+	problems := []string{}
+	opts := []LogmanOptions{}
+	if c.AppName != "" {
+		opts = append(opts, WithAppName(c.AppName))
+	}
+
+	known := make(map[string]int)
+	for name, lvl := range defaultLoggingLevels() {
+		known[name] = lvl.importance
+	}
+	levels := []*loggingLevel{}
+	seen := make(map[string]bool)
+	for i, lc := range c.Levels {
+		path := fmt.Sprintf("levels[%v]", i)
+		if lc.Name == "" {
+			problems = append(problems, path+".name: must not be empty")
+			continue
+		}
+		if seen[lc.Name] {
+			problems = append(problems, fmt.Sprintf("%v.name: duplicate level '%v'", path, lc.Name))
+			continue
+		}
+		seen[lc.Name] = true
+		lvlOpts := []LevelOpts{LevelCallerInfo(lc.CallerInfo), LevelExitWhenDone(lc.Exit)}
+		if lc.Tag != "" {
+			lvlOpts = append(lvlOpts, LevelTag(lc.Tag))
+		}
+		importance := ImportanceINFO
+		if lc.Importance != "" {
+			imp, err := parseImportance(lc.Importance, nil)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%v.importance: %v", path, err))
+			}
+			importance = imp
+		}
+		lvlOpts = append(lvlOpts, LevelImportance(importance))
+		known[lc.Name] = importance
+		levels = append(levels, NewLoggingLevel(lc.Name, lvlOpts...))
+		if sc := lc.Sampling; sc != nil {
+			if sc.First < 0 || sc.Thereafter < 0 || sc.Rate < 0 || sc.Burst < 0 || sc.Interval < 0 || sc.Summary < 0 {
+				problems = append(problems, path+".sampling: values must not be negative")
+			}
+			if sc.First > 0 && sc.Interval == 0 {
+				problems = append(problems, path+".sampling.interval: must be set with first")
+			}
+			if sc.Rate > 0 && sc.Burst < 1 {
+				problems = append(problems, path+".sampling.burst: must be positive with rate")
+			}
+			opts = append(opts, WithSampling(lc.Name,
+				SampleFirst(sc.First, sc.Interval),
+				SampleThereafter(sc.Thereafter),
+				SampleRate(sc.Rate, sc.Burst),
+				SampleSummary(sc.Summary),
+			))
+		}
+	}
+	if len(levels) > 0 {
+		opts = append(opts, WithLogLevels(levels...))
+	}
+
+	if c.Importance != "" {
+		imp, err := parseImportance(c.Importance, known)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("importance: %v", err))
+		}
+		opts = append(opts, WithAppLogLevelImportance(imp))
+	}
+
+	if c.Verbosity != "" {
+		if _, err := parseVerbosity(c.Verbosity, known); err != nil {
+			problems = append(problems, fmt.Sprintf("verbosity: %v", err))
+		}
+		opts = append(opts, WithVerbosity(c.Verbosity))
+	}
+
+	var scheme Colorizer
+	if c.Colors != nil {
+		colors, colorProblems := c.Colors.colorizer()
+		problems = append(problems, colorProblems...)
+		if colors != nil {
+			scheme = colors
+			opts = append(opts, WithGlobalColorizer(colors))
+		}
+	}
+
+	writerKeys := make(map[string]bool)
+	for i, wc := range c.Writers {
+		path := fmt.Sprintf("writers[%v]", i)
+		writerOpts, writerProblems := wc.options(path, known, scheme)
+		problems = append(problems, writerProblems...)
+		if writerKeys[wc.Key] && wc.Key != "" {
+			problems = append(problems, fmt.Sprintf("%v.key: duplicate writer '%v'", path, wc.Key))
+		}
+		writerKeys[wc.Key] = true
+		opts = append(opts, writerOpts...)
+	}
+	if len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+	return opts, nil
+}
+
+// parseImportance accepts number or level name. known levels extend standard names.
+func parseImportance(value string, known map[string]int) (int, error) {
+	value = strings.TrimSpace(value)
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < ImportanceALL || n > ImportanceNONE {
+			return ImportanceALL, fmt.Errorf("importance %v is out of range [%v..%v]", n, ImportanceALL, ImportanceNONE)
+		}
+		return n, nil
+	}
+	if imp, ok := known[value]; ok {
+		return imp, nil
+	}
+	if imp, ok := importanceNames[strings.ToLower(value)]; ok {
+		return imp, nil
+	}
+	return ImportanceALL, fmt.Errorf("unknown importance '%v'", value)
+}
+
+func (cc *ColorConfig) colorizer() (Colorizer, []string) {
+	problems := []string{}
+	switch strings.ToLower(cc.Scheme) {
+	case "", "default":
+	case "none":
+		if len(cc.FG)+len(cc.BG) > 0 {
+			problems = append(problems, "colors: custom colors are set for scheme 'none'")
+		}
+		return nil, problems
+	default:
+		return nil, append(problems, fmt.Sprintf("colors.scheme: unknown scheme '%v'", cc.Scheme))
+	}
+	scheme := colorizer.DefaultScheme()
+	for keyType, colors := range map[string]map[string]uint8{colorizer.FG_KEY: cc.FG, colorizer.BG_KEY: cc.BG} {
+		for key, val := range colors {
+			scheme = scheme.WithColors(colorizer.CustomColor(colorizer.NewKey(keyType, key), val))
+		}
+	}
+	return scheme, problems
+}
+
+func (wc WriterConfig) options(path string, known map[string]int, scheme Colorizer) ([]LogmanOptions, []string) {
+	problems := []string{}
+	opts := []LogmanOptions{}
+	if wc.Key == "" {
+		problems = append(problems, path+".key: must not be empty")
+	}
+	fmtOpts := []FormatterOption{}
+	switch strings.ToLower(wc.Format) {
+	case "", "text":
+		if len(wc.Fields) > 0 {
+			fields, err := requestedFields(wc.Fields)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%v.fields: %v", path, err))
+			}
+			fmtOpts = append(fmtOpts, WithRequestedFields(fields))
+		}
+	case "json":
+		if len(wc.Fields) > 0 {
+			problems = append(problems, path+".fields: not used with json format")
+		}
+		fmtOpts = append(fmtOpts, WithCustomFunc("json", stdJSON), WithRequestedFields([]string{"json"}))
+	default:
+		problems = append(problems, fmt.Sprintf("%v.format: unknown format '%v'", path, wc.Format))
+	}
+	if wc.Color != nil {
+		switch *wc.Color {
+		case true:
+			if scheme == nil {
+				problems = append(problems, path+".color: no color scheme configured")
+			}
+			fmtOpts = append(fmtOpts, WithColor(scheme))
+		case false:
+			fmtOpts = append(fmtOpts, WithColor(nil))
+		}
+	}
+	writerOpts := []WriterOption{}
+	if wc.Importance != "" {
+		imp, err := parseImportance(wc.Importance, known)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%v.importance: %v", path, err))
+		}
+		writerOpts = append(writerOpts, WriterMinImportance(imp))
+	}
+	for key, value := range wc.Match {
+		writerOpts = append(writerOpts, WriterFieldEquals(key, value))
+	}
+	for _, level := range wc.Levels {
+		if _, ok := known[level]; !ok {
+			problems = append(problems, fmt.Sprintf("%v.levels: unknown level '%v'", path, level))
+		}
+	}
+	if wc.Rotation != nil {
+		switch wc.Key {
+		case Stdout, Stderr:
+			problems = append(problems, path+".rotation: console writer can not be rotated")
+		default:
+			opts = append(opts, WithRotatingFile(wc.Key, wc.Rotation.options()...))
+		}
+		if wc.Rotation.MaxSize < 0 || wc.Rotation.Keep < 0 || wc.Rotation.Period < 0 || wc.Rotation.MaxAge < 0 {
+			problems = append(problems, path+".rotation: values must not be negative")
+		}
+	}
+	formatter := NewFormatter(fmtOpts...)
+	switch len(wc.Levels) {
+	case 0:
+		opts = append(opts, WithGlobalWriterFormatter(wc.Key, formatter, writerOpts...))
+	default:
+		levels := append([]string{}, wc.Levels...)
+		key := wc.Key
+		filter := newWriterFilter(writerOpts...)
+		opts = append(opts, func(o *options) {
+			for _, level := range levels {
+				o.levelWriters = append(o.levelWriters, levelWriter{level: level, writerKey: key, formatter: formatter, filter: filter})
+			}
+		})
+	}
+	return opts, problems
+}
+
+func (rc *RotationConfig) options() []RotationOption {
+	return []RotationOption{
+		RotationMaxSize(rc.MaxSize),
+		RotationPeriod(rc.Period),
+		RotationKeep(rc.Keep),
+		RotationMaxAge(rc.MaxAge),
+		RotationCompress(rc.Compress),
+	}
+}
+
+// requestedFields expands single preset name or checks list of field names.
+func requestedFields(fields []string) ([]string, error) {
+	if len(fields) == 1 {
+		if preset, ok := fieldPresets[fields[0]]; ok {
+			return preset, nil
+		}
+	}
+	for _, field := range fields {
+		if strings.TrimSpace(field) == "" {
+			return fields, fmt.Errorf("field name must not be empty")
+		}
+		if _, ok := fieldPresets[field]; ok {
+			return fields, fmt.Errorf("preset '%v' must be used alone", field)
+		}
+	}
+	return fields, nil
+}
+
+// configDecoder converts generic tree decoded from JSON or YAML into Config
+// collecting every problem found instead of stopping on first one.
+type configDecoder struct {
+	problems []string
+}
+
+func (d *configDecoder) problemf(path, format string, args ...interface{}) {
+	d.problems = append(d.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (d *configDecoder) config(tree interface{}) *Config {
+	cfg := &Config{}
+	if tree == nil {
+		return cfg
+	}
+	m := d.object(tree, "config", "app_name", "importance", "verbosity", "colors", "levels", "writers")
+	if m == nil {
+		return cfg
+	}
+	cfg.AppName = d.str(m, "app_name", "")
+	cfg.Importance = d.importance(m, "importance", "")
+	cfg.Verbosity = d.str(m, "verbosity", "")
+	if v, ok := m["colors"]; ok {
+		cfg.Colors = d.colors(v, "colors")
+	}
+	for i, v := range d.list(m, "levels", "") {
+		if lc, ok := d.level(v, fmt.Sprintf("levels[%v]", i)); ok {
+			cfg.Levels = append(cfg.Levels, lc)
+		}
+	}
+	for i, v := range d.list(m, "writers", "") {
+		if wc, ok := d.writer(v, fmt.Sprintf("writers[%v]", i)); ok {
+			cfg.Writers = append(cfg.Writers, wc)
+		}
+	}
+	return cfg
+}
+
+func (d *configDecoder) colors(v interface{}, path string) *ColorConfig {
+	m := d.object(v, path, "scheme", "fg", "bg")
+	if m == nil {
+		return nil
+	}
+	return &ColorConfig{
+		Scheme: d.str(m, "scheme", path),
+		FG:     d.colorMap(m, "fg", path),
+		BG:     d.colorMap(m, "bg", path),
+	}
+}
+
+func (d *configDecoder) level(v interface{}, path string) (LevelConfig, bool) {
+	m := d.object(v, path, "name", "tag", "importance", "caller_info", "exit", "sampling")
+	if m == nil {
+		return LevelConfig{}, false
+	}
+	lc := LevelConfig{
+		Name:       d.str(m, "name", path),
+		Tag:        d.str(m, "tag", path),
+		Importance: d.importance(m, "importance", path),
+		CallerInfo: isTrue(d.boolean(m, "caller_info", path)),
+		Exit:       isTrue(d.boolean(m, "exit", path)),
+	}
+	if sv, ok := m["sampling"]; ok {
+		spath := joinPath(path, "sampling")
+		sm := d.object(sv, spath, "first", "interval", "thereafter", "rate", "burst", "summary")
+		if sm != nil {
+			lc.Sampling = &SamplingConfig{
+				First:      int(d.integer(sm, "first", spath)),
+				Interval:   d.duration(sm, "interval", spath),
+				Thereafter: int(d.integer(sm, "thereafter", spath)),
+				Rate:       d.number(sm, "rate", spath),
+				Burst:      int(d.integer(sm, "burst", spath)),
+				Summary:    d.duration(sm, "summary", spath),
+			}
+		}
+	}
+	return lc, true
+}
+
+func (d *configDecoder) writer(v interface{}, path string) (WriterConfig, bool) {
+	m := d.object(v, path, "key", "fields", "format", "color", "levels", "rotation", "importance", "match")
+	if m == nil {
+		return WriterConfig{}, false
+	}
+	wc := WriterConfig{
+		Key:    d.str(m, "key", path),
+		Fields: d.strList(m, "fields", path),
+		Format: d.str(m, "format", path),
+		Color:  d.boolean(m, "color", path),
+		Levels: d.strList(m, "levels", path),
+
+		Importance: d.importance(m, "importance", path),
+		Match:      d.scalarMap(m, "match", path),
+	}
+	if rv, ok := m["rotation"]; ok {
+		rpath := joinPath(path, "rotation")
+		rm := d.object(rv, rpath, "max_size", "period", "keep", "max_age", "compress")
+		if rm != nil {
+			wc.Rotation = &RotationConfig{
+				MaxSize:  d.integer(rm, "max_size", rpath),
+				Period:   d.duration(rm, "period", rpath),
+				Keep:     int(d.integer(rm, "keep", rpath)),
+				MaxAge:   d.duration(rm, "max_age", rpath),
+				Compress: isTrue(d.boolean(rm, "compress", rpath)),
+			}
+		}
+	}
+	return wc, true
+}
+
+// object checks v is object which keys are all allowed.
+func (d *configDecoder) object(v interface{}, path string, allowed ...string) map[string]interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		d.problemf(path, "expected object, have %v", describe(v))
+		return nil
+	}
+	unknown := []string{}
+	for key := range m {
+		if !slices.Contains(allowed, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		d.problemf(joinPath(path, key), "unknown key")
+	}
+	return m
+}
+
+func (d *configDecoder) list(m map[string]interface{}, key, path string) []interface{} {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		d.problemf(joinPath(path, key), "expected list, have %v", describe(v))
+	}
+	return list
+}
+
+func (d *configDecoder) str(m map[string]interface{}, key, path string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		d.problemf(joinPath(path, key), "expected string, have %v", describe(v))
+	}
+	return s
+}
+
+// strList accepts list of strings or single string.
+func (d *configDecoder) strList(m map[string]interface{}, key, path string) []string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	list := []string{}
+	for i, item := range d.list(m, key, path) {
+		s, ok := item.(string)
+		if !ok {
+			d.problemf(fmt.Sprintf("%v[%v]", joinPath(path, key), i), "expected string, have %v", describe(item))
+			continue
+		}
+		list = append(list, s)
+	}
+	return list
+}
+
+func (d *configDecoder) boolean(m map[string]interface{}, key, path string) *bool {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		d.problemf(joinPath(path, key), "expected boolean, have %v", describe(v))
+		return nil
+	}
+	return &b
+}
+
+func (d *configDecoder) integer(m map[string]interface{}, key, path string) int64 {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return 0
+	}
+	n, ok := integerValue(v)
+	if !ok {
+		d.problemf(joinPath(path, key), "expected integer, have %v", describe(v))
+	}
+	return n
+}
+
+func (d *configDecoder) number(m map[string]interface{}, key, path string) float64 {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return 0
+	}
+	if n, ok := v.(float64); ok {
+		return n
+	}
+	n, ok := integerValue(v)
+	if !ok {
+		d.problemf(joinPath(path, key), "expected number, have %v", describe(v))
+	}
+	return float64(n)
+}
+
+// importance accepts level name or integer and returns it as string for parseImportance.
+func (d *configDecoder) importance(m map[string]interface{}, key, path string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if n, ok := integerValue(v); ok {
+		return strconv.FormatInt(n, 10)
+	}
+	return d.str(m, key, path)
+}
+
+func (d *configDecoder) duration(m map[string]interface{}, key, path string) time.Duration {
+	s := d.str(m, key, path)
+	if s == "" {
+		return 0
+	}
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		d.problemf(joinPath(path, key), "bad duration '%v'", s)
+	}
+	return dur
+}
+
+// scalarMap accepts object of strings, numbers or booleans and prints values.
+func (d *configDecoder) scalarMap(m map[string]interface{}, key, path string) map[string]string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	path = joinPath(path, key)
+	sm, ok := v.(map[string]interface{})
+	if !ok {
+		d.problemf(path, "expected object, have %v", describe(v))
+		return nil
+	}
+	values := make(map[string]string)
+	for name, val := range sm {
+		switch val.(type) {
+		case string, bool, int, int64, uint64, float64:
+			values[name] = fmt.Sprint(val)
+		default:
+			d.problemf(joinPath(path, name), "expected scalar value, have %v", describe(val))
+		}
+	}
+	return values
+}
+
+func (d *configDecoder) colorMap(m map[string]interface{}, key, path string) map[string]uint8 {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	path = joinPath(path, key)
+	cm, ok := v.(map[string]interface{})
+	if !ok {
+		d.problemf(path, "expected object, have %v", describe(v))
+		return nil
+	}
+	colors := make(map[string]uint8)
+	for name, val := range cm {
+		n, ok := integerValue(val)
+		if !ok || n < 0 || n > 255 {
+			d.problemf(joinPath(path, name), "expected color256 value [0..255], have %v", describe(val))
+			continue
+		}
+		colors[name] = uint8(n)
+	}
+	return colors
+}
+
+func integerValue(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	case float64:
+		return int64(n), n == math.Trunc(n)
+	}
+	return 0, false
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+func describe(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "list"
+	case string:
+		return fmt.Sprintf("string '%v'", v)
+	}
+	return fmt.Sprintf("%T %v", v, v)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,140 @@
+package logman
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	configPath := filepath.Join(dir, "logman.yaml")
+	config := `
+app_name: scribe
+importance: warn
+levels:
+  - {name: info, tag: INFO, importance: 50}
+  - {name: audit, importance: info}
+writers:
+  - key: ` + logPath + `
+    fields: message_only
+    levels: [info, audit]
+`
+	if err := os.WriteFile(configPath, []byte(config), 0666); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvImportance, "info")
+	lgr, err := NewFromConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lgr.appName != "scribe" || lgr.state.Load().minimum != ImportanceINFO {
+		t.Errorf("unexpected app settings: %v %v", lgr.appName, lgr.state.Load().minimum)
+	}
+	lgr.Info("from %v", "config")
+	lgr.ProcessMessage(NewMessage("audited"), "audit")
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	bt, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bt) != "from config \naudited \n" {
+		t.Errorf("unexpected log %q", string(bt))
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	_, err := ParseConfig([]byte(`{
+		"app_name": 5,
+		"colour": "none",
+		"levels": [{"name": "audit", "importance": 120}, {"name": "audit"}],
+		"writers": [
+			{"key": "StdErr", "format": "xml", "levels": ["nope"], "rotation": {"period": "daily"}},
+			{"key": "", "fields": ["time", ""], "color": "yes"}
+		]
+	}`), "json")
+	ce := &ConfigError{}
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected ConfigError, have %v", err)
+	}
+	expected := []string{
+		"config.colour: unknown key",
+		"app_name: expected string",
+		"writers[0].rotation.period: bad duration 'daily'",
+		"writers[1].color: expected boolean",
+	}
+	if len(ce.Problems) != len(expected) {
+		t.Errorf("expected %v problems, have %v", len(expected), ce.Problems)
+	}
+	for _, problem := range expected {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("problem %q not reported in:\n%v", problem, err)
+		}
+	}
+
+	cfg, err := ParseConfig([]byte(`{
+		"levels": [{"name": "audit", "importance": 120}, {"name": "audit"}],
+		"writers": [
+			{"key": "StdErr", "format": "xml", "levels": ["nope"], "rotation": {"keep": 2}},
+			{"key": "", "fields": ["time", ""]}
+		]
+	}`), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cfg.Options()
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected ConfigError, have %v", err)
+	}
+	expected = []string{
+		"levels[0].importance: importance 120 is out of range",
+		"levels[1].name: duplicate level 'audit'",
+		"writers[0].format: unknown format 'xml'",
+		"writers[0].levels: unknown level 'nope'",
+		"writers[0].rotation: console writer can not be rotated",
+		"writers[1].key: must not be empty",
+		"writers[1].fields: field name must not be empty",
+	}
+	if len(ce.Problems) != len(expected) {
+		t.Errorf("expected %v problems, have %v", len(expected), ce.Problems)
+	}
+	for _, problem := range expected {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("problem %q not reported in:\n%v", problem, err)
+		}
+	}
+}
+
+func TestSetupFromConfigReplacesDefault(t *testing.T) {
+	original := Default()
+	defer SetDefault(original)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "logman.yaml")
+	config := "writers:\n  - key: " + filepath.Join(dir, "app.log") + "\n    fields: message_only\n"
+	if err := os.WriteFile(configPath, []byte(config), 0666); err != nil {
+		t.Fatal(err)
+	}
+	first := &closeTracker{}
+	if err := Setup(WithWriterNamed("first", first, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetupFromConfig(configPath); err != nil {
+		t.Fatal(err)
+	}
+	if !first.closed {
+		t.Errorf("default logger replaced by SetupFromConfig is not closed")
+	}
+	fromConfig := Default()
+	if err := Setup(); err != nil {
+		t.Fatal(err)
+	}
+	if !fromConfig.state.Load().epoch.writers.closed {
+		t.Errorf("default logger set by SetupFromConfig is not closed by Setup")
+	}
+}
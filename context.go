@@ -0,0 +1,175 @@
+package logman
+
+import (
+	"context"
+	"fmt"
+)
+
+type contextKey int
+
+const (
+	contextKeyLogger contextKey = iota
+	contextKeyFields
+	contextKeyImportance
+)
+
+// NewContext returns copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKeyLogger, l)
+}
+
+// FromContext returns Logger stored in ctx by NewContext or default Logger.
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKeyLogger).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return Default()
+}
+
+// ContextWithFields returns copy of ctx carrying request-scoped fields
+// (request id, user id, trace id...). Fields already stored in ctx are kept unless overridden by key.
+// Fields are added to every message processed with this context.
+func ContextWithFields(ctx context.Context, fields ...messageField) context.Context {
+	current := FieldsFromContext(ctx)
+	merged := make([]messageField, len(current), len(current)+len(fields))
+	copy(merged, current)
+	for _, fld := range fields {
+		merged = setBoundField(merged, fld)
+	}
+	return context.WithValue(ctx, contextKeyFields, merged)
+}
+
+// FieldsFromContext returns fields stored in ctx by ContextWithFields.
+func FieldsFromContext(ctx context.Context) []messageField {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextKeyFields).([]messageField)
+	return fields
+}
+
+// ContextWithImportance returns copy of ctx overriding minimum message importance
+// for messages processed with this context. Use it to trace single request at
+// ImportanceTRACE while application minimum stays higher.
+func ContextWithImportance(ctx context.Context, importance int) context.Context {
+	if importance > ImportanceNONE {
+		importance = ImportanceNONE
+	}
+	if importance < ImportanceALL {
+		importance = ImportanceALL
+	}
+	return context.WithValue(ctx, contextKeyImportance, importance)
+}
+
+// ImportanceFromContext returns minimum importance override stored in ctx by ContextWithImportance.
+func ImportanceFromContext(ctx context.Context) (int, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	importance, ok := ctx.Value(contextKeyImportance).(int)
+	return importance, ok
+}
+
+// WithContextFields - adds function extracting fields from context (for example trace id
+// stored by tracing library). Extracted fields are added to messages processed with context.
+func WithContextFields(extract func(context.Context) []messageField) LogmanOptions {
+	return func(o *options) {
+		o.contextExtractors = append(o.contextExtractors, extract)
+	}
+}
+
+// contextFields returns fields of ctx: stored by ContextWithFields first, then extracted.
+func (l *Logger) contextFields(ctx context.Context) []messageField {
+	fields := FieldsFromContext(ctx)
+	if len(l.contextExtractors) == 0 {
+		return fields
+	}
+	fields = append([]messageField{}, fields...)
+	for _, extract := range l.contextExtractors {
+		for _, fld := range extract(ctx) {
+			fields = setBoundField(fields, fld)
+		}
+	}
+	return fields
+}
+
+// ProcessMessageCtx is ProcessMessage using request-scoped fields and importance override of ctx.
+func (l *Logger) ProcessMessageCtx(ctx context.Context, msg Message, levels ...string) error {
+	return l.processCtx(ctx, 1, msg, levels...)
+}
+
+// InfoCtx formats message according to a format specifier and writes to output writers of Level INFO
+// adding fields of ctx.
+func (l *Logger) InfoCtx(ctx context.Context, format string, args ...interface{}) error {
+	return l.logfCtx(ctx, 1, INFO, format, args...)
+}
+
+// WarnCtx formats message according to a format specifier and writes to output writers of Level WARN
+// adding fields of ctx.
+func (l *Logger) WarnCtx(ctx context.Context, format string, args ...interface{}) error {
+	return l.logfCtx(ctx, 1, WARN, format, args...)
+}
+
+// ErrorfCtx formats message according to a format specifier and writes to output writers of Level ERROR
+// adding fields of ctx. It returns message processing error encountered or error created if processing is success.
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, args ...interface{}) error {
+	errCreated := fmt.Errorf(format, args...)
+	if err := l.errorCtx(ctx, 1, errCreated, wrapVerbsAsV(format), args); err != nil {
+		return err
+	}
+	return errCreated
+}
+
+// DebugCtx writes message to writers of Level DEBUG adding fields of ctx.
+func (l *Logger) DebugCtx(ctx context.Context, msg Message) error {
+	return l.processCtx(ctx, 1, msg, DEBUG)
+}
+
+// TraceCtx writes message to writers of Level TRACE adding fields of ctx.
+func (l *Logger) TraceCtx(ctx context.Context, msg Message) error {
+	return l.processCtx(ctx, 1, msg, TRACE)
+}
+
+func (l *Logger) logfCtx(ctx context.Context, depth int, level, format string, args ...interface{}) error {
+	if !l.enabled(ctx, depth+1, level) {
+		return nil
+	}
+	return l.processCtx(ctx, depth+1, newPooledMessage(format, args), level)
+}
+
+// ProcessMessageCtx processes message by Logger of ctx (see FromContext).
+func ProcessMessageCtx(ctx context.Context, msg Message, levels ...string) error {
+	return FromContext(ctx).processCtx(ctx, 1, msg, levels...)
+}
+
+// InfoCtx writes message on Level INFO of Logger of ctx (see FromContext) adding fields of ctx.
+func InfoCtx(ctx context.Context, format string, args ...interface{}) error {
+	return FromContext(ctx).logfCtx(ctx, 1, INFO, format, args...)
+}
+
+// WarnCtx writes message on Level WARN of Logger of ctx (see FromContext) adding fields of ctx.
+func WarnCtx(ctx context.Context, format string, args ...interface{}) error {
+	return FromContext(ctx).logfCtx(ctx, 1, WARN, format, args...)
+}
+
+// ErrorfCtx writes message on Level ERROR of Logger of ctx (see FromContext) adding fields of ctx.
+// It returns message processing error encountered or error created if processing is success.
+func ErrorfCtx(ctx context.Context, format string, args ...interface{}) error {
+	errCreated := fmt.Errorf(format, args...)
+	if err := FromContext(ctx).errorCtx(ctx, 1, errCreated, wrapVerbsAsV(format), args); err != nil {
+		return err
+	}
+	return errCreated
+}
+
+// DebugCtx writes message on Level DEBUG of Logger of ctx (see FromContext) adding fields of ctx.
+func DebugCtx(ctx context.Context, msg Message) error {
+	return FromContext(ctx).processCtx(ctx, 1, msg, DEBUG)
+}
+
+// TraceCtx writes message on Level TRACE of Logger of ctx (see FromContext) adding fields of ctx.
+func TraceCtx(ctx context.Context, msg Message) error {
+	return FromContext(ctx).processCtx(ctx, 1, msg, TRACE)
+}
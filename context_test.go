@@ -0,0 +1,47 @@
+package logman
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type traceKey struct{}
+
+func TestContextLogging(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewFormatter(WithRequestedFields([]string{keyLevel, keyMessage, "request", "trace"}))
+	lgr, err := New(
+		WithLogLevels(
+			NewLoggingLevel(INFO),
+			NewLoggingLevel(TRACE, LevelImportance(ImportanceTRACE)),
+		),
+		WithAppLogLevelImportance(ImportanceINFO),
+		WithWriterNamed("buffer", buf, formatter),
+		WithContextFields(func(ctx context.Context) []messageField {
+			if id, ok := ctx.Value(traceKey{}).(string); ok {
+				return []messageField{NewField("trace", id)}
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := NewContext(context.Background(), lgr)
+	ctx = ContextWithFields(ctx, NewField("request", "r-1"))
+	ctx = context.WithValue(ctx, traceKey{}, "t-9")
+
+	TraceCtx(ctx, NewMessage("hidden"))
+	InfoCtx(ctx, "shown")
+	traced := ContextWithImportance(ctx, ImportanceTRACE)
+	TraceCtx(traced, NewMessage("traced"))
+	lgr.Info("no context")
+
+	expected := "[info] shown request=r-1 trace=t-9 \n" +
+		"[trace] traced request=r-1 trace=t-9 \n" +
+		"[info] no context request=<nil> trace=<nil> \n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\nhave:\n%v", expected, buf.String())
+	}
+}
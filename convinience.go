@@ -1,6 +1,7 @@
 package logman
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -13,11 +14,13 @@ import (
 // It returns message processing error encountered.
 // Same as func: Info(format, args...) (error) .
 func Printf(format string, args ...interface{}) error {
-	msg := NewMessage(format, args...)
-	if err := process(msg, logMan.logLevels[INFO]); err != nil {
-		return err
-	}
-	return nil
+	return Default().logf(1, INFO, format, args...)
+}
+
+// Printf formats message according to a format specifier and writes to output writers of Level INFO.
+// Same as method: Info(format, args...) (error) .
+func (l *Logger) Printf(format string, args ...interface{}) error {
+	return l.logf(1, INFO, format, args...)
 }
 
 // This is a convinience function for ProcessMessage.
@@ -25,16 +28,21 @@ func Printf(format string, args ...interface{}) error {
 // with spaces.
 // It returns message processing error encountered.
 func Println(args ...interface{}) error {
+	return Default().logf(1, INFO, lnFormat(args), args...)
+}
+
+// Println writes to output writers of Level INFO. Args are separated
+// with spaces.
+func (l *Logger) Println(args ...interface{}) error {
+	return l.logf(1, INFO, lnFormat(args), args...)
+}
+
+func lnFormat(args []interface{}) string {
 	format := ""
 	for range args {
 		format += "%v "
 	}
-	format = strings.TrimSuffix(format, " ")
-	msg := NewMessage(format, args...)
-	if err := process(msg, logMan.logLevels[INFO]); err != nil {
-		return err
-	}
-	return nil
+	return strings.TrimSuffix(format, " ")
 }
 
 // This is a convinience function for ProcessMessage.
@@ -42,20 +50,33 @@ func Println(args ...interface{}) error {
 // It returns message processing error encountered or error created if processing is success.
 // By default calling level Fatal cause os.Exit(1) after completion (subject to change during logger setup process).
 func Fatalf(format string, args ...interface{}) error {
-	msg := NewMessage(format, args...)
-	if err := process(msg, logMan.logLevels[FATAL]); err != nil {
-		return err
-	}
-	return nil
+	return Default().logf(1, FATAL, format, args...)
+}
+
+// Fatalf formats message according to a format specifier and writes to output writers of Level FATAL.
+// By default calling level Fatal cause os.Exit(1) after completion.
+func (l *Logger) Fatalf(format string, args ...interface{}) error {
+	return l.logf(1, FATAL, format, args...)
 }
 
 // This is a convinience function for ProcessMessage.
 // Errorf formats message according to a format specifier and writes to output writers of Level ERROR.
 // It returns message processing error encountered or error created if processing is success.
 func Errorf(format string, args ...interface{}) error {
+	return Default().errorf(1, format, args...)
+}
+
+// Errorf formats message according to a format specifier and writes to output writers of Level ERROR.
+// It returns message processing error encountered or error created if processing is success.
+func (l *Logger) Errorf(format string, args ...interface{}) error {
+	return l.errorf(1, format, args...)
+}
+
+// errorf creates error with fmt.Errorf, so operands of %w are wrapped.
+// Message keeps format and arguments, so it is sampled and colored as any other message.
+func (l *Logger) errorf(depth int, format string, args ...interface{}) error {
 	errCreated := fmt.Errorf(format, args...)
-	msg := NewMessage(format, args...)
-	if errProcessing := process(msg, logMan.logLevels[ERROR]); errProcessing != nil {
+	if errProcessing := l.errorCtx(nil, depth+1, errCreated, wrapVerbsAsV(format), args); errProcessing != nil {
 		return errProcessing
 	}
 	return errCreated
@@ -65,30 +86,64 @@ func Errorf(format string, args ...interface{}) error {
 // Error creates message input argument and writes to output writers of Level ERROR.
 // It returns message processing error encountered or input error if processing is success.
 func Error(errInput error) error {
-	msg := NewMessage(errInput.Error())
-	if errProcessing := process(msg, logMan.logLevels[ERROR]); errProcessing != nil {
+	return Default().error(1, errInput)
+}
+
+// Error creates message input argument and writes to output writers of Level ERROR.
+// It returns message processing error encountered or input error if processing is success.
+func (l *Logger) Error(errInput error) error {
+	return l.error(1, errInput)
+}
+
+func (l *Logger) error(depth int, errInput error) error {
+	if errProcessing := l.errorCtx(nil, depth+1, errInput, escapeFormat(errInput.Error()), nil); errProcessing != nil {
 		return errProcessing
 	}
 	return errInput
 }
 
+// errorCtx writes message on Level ERROR with error kept in field "error".
+// Stack trace of the call site is captured if Logger is created WithErrorStack.
+func (l *Logger) errorCtx(ctx context.Context, depth int, errInput error, format string, args []interface{}) error {
+	if !l.enabled(ctx, depth+1, ERROR) {
+		return nil
+	}
+	msg := newPooledMessage(format, args)
+	msg.SetField(keyError, newErrorValue(errInput, depth+1, l.errorStack))
+	return l.processCtx(ctx, depth+1, msg, ERROR)
+}
+
 // This is a convinience function for ProcessMessage.
 // Warn formats message according to a format specifier and writes to output writers of Level WARN.
 // It returns message processing error encountered.
 func Warn(format string, args ...interface{}) error {
-	msg := NewMessage(format, args...)
-	if err := process(msg, logMan.logLevels[WARN]); err != nil {
-		return err
-	}
-	return nil
+	return Default().logf(1, WARN, format, args...)
+}
+
+// Warn formats message according to a format specifier and writes to output writers of Level WARN.
+func (l *Logger) Warn(format string, args ...interface{}) error {
+	return l.logf(1, WARN, format, args...)
 }
 
 // This is a convinience function for ProcessMessage.
 // Info formats message according to a format specifier and writes to output writers of Level INFO.
 // It returns message processing error encountered.
 func Info(format string, args ...interface{}) error {
-	msg := NewMessage(format, args...)
-	if err := process(msg, logMan.logLevels[INFO]); err != nil {
+	return Default().logf(1, INFO, format, args...)
+}
+
+// Info formats message according to a format specifier and writes to output writers of Level INFO.
+func (l *Logger) Info(format string, args ...interface{}) error {
+	return l.logf(1, INFO, format, args...)
+}
+
+// logf skips creating message if level is not enabled.
+func (l *Logger) logf(depth int, level, format string, args ...interface{}) error {
+	if !l.enabled(nil, depth+1, level) {
+		return nil
+	}
+	msg := newPooledMessage(format, args)
+	if err := l.process(depth+1, msg, level); err != nil {
 		return err
 	}
 	return nil
@@ -99,14 +154,13 @@ func Info(format string, args ...interface{}) error {
 // Comments will be printed to os.Stderr EVEN if message will not be processed.
 // It returns message processing error encountered.
 func Debug(msg Message, comments ...string) error {
-	for _, comment := range comments {
-		comment = color.S256(253).Sprintf("%v", comment)
-		fmt.Fprintf(os.Stderr, "#%v\n", comment)
-	}
-	if err := process(msg, logMan.logLevels[DEBUG]); err != nil {
-		return err
-	}
-	return nil
+	return Default().commented(1, DEBUG, msg, comments...)
+}
+
+// Debug receives message with additional comments. Message will be written to writers of Level DEBUG.
+// Comments will be printed to os.Stderr EVEN if message will not be processed.
+func (l *Logger) Debug(msg Message, comments ...string) error {
+	return l.commented(1, DEBUG, msg, comments...)
 }
 
 // This is a convinience function for ProcessMessage.
@@ -114,11 +168,21 @@ func Debug(msg Message, comments ...string) error {
 // Comments will be printed to os.Stderr EVEN if message will not be processed.
 // It returns message processing error encountered.
 func Trace(msg Message, comments ...string) error {
+	return Default().commented(1, TRACE, msg, comments...)
+}
+
+// Trace receives message with  additional comments. Message will be written to writers of Level TRACE.
+// Comments will be printed to os.Stderr EVEN if message will not be processed.
+func (l *Logger) Trace(msg Message, comments ...string) error {
+	return l.commented(1, TRACE, msg, comments...)
+}
+
+func (l *Logger) commented(depth int, level string, msg Message, comments ...string) error {
 	for _, comment := range comments {
 		comment = color.S256(253).Sprintf("%v", comment)
 		fmt.Fprintf(os.Stderr, "#%v\n", comment)
 	}
-	if err := process(msg, logMan.logLevels[TRACE]); err != nil {
+	if err := l.process(depth+1, msg, level); err != nil {
 		return err
 	}
 	return nil
@@ -131,8 +195,18 @@ func Trace(msg Message, comments ...string) error {
 //
 // Never return error.
 func Ping(comments ...string) error {
-	msg := NewMessage("")
-	if err := process(msg, logMan.logLevels[PING]); err != nil {
+	return Default().ping(1, comments...)
+}
+
+// Ping receives comments. Message with code location will be created and written to writers of Level PING.
+// Never return error.
+func (l *Logger) Ping(comments ...string) error {
+	return l.ping(1, comments...)
+}
+
+func (l *Logger) ping(depth int, comments ...string) error {
+	msg := newPooledMessage("", nil)
+	if err := l.process(depth+1, msg, PING); err != nil {
 		fmt.Fprintf(os.Stderr, "ping error: %v\n", err)
 	}
 	for _, comment := range comments {
@@ -0,0 +1,156 @@
+package logman
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const keyRepeated = "repeated"
+
+// DedupKey decides which messages are identical for deduplication.
+type DedupKey int
+
+const (
+	// DedupMessage treats messages with the same rendered text as identical.
+	DedupMessage DedupKey = iota
+	// DedupTemplate treats messages with the same format string as identical.
+	DedupTemplate
+)
+
+type dedupSettings struct {
+	by      DedupKey
+	timeout time.Duration
+	levels  []string
+}
+
+// WithDedup - collapses consecutive identical messages of levels (of all levels if none):
+// first message is written, repeats are counted and reported by single
+// "... repeated N times" message (with field "repeated") when different message arrives
+// on level or when no repeat comes for timeout. Levels that exit program are never collapsed.
+func WithDedup(by DedupKey, timeout time.Duration, levels ...string) LogmanOptions {
+	return func(o *options) {
+		o.dedup = &dedupSettings{by: by, timeout: timeout, levels: levels}
+	}
+}
+
+// dedups creates deduplicators of levels.
+func (opt *options) dedups(levels levelTable) (map[string]*deduplicator, error) {
+	if opt.dedup == nil {
+		return nil, nil
+	}
+	names := opt.dedup.levels
+	if len(names) == 0 {
+		for name := range levels {
+			names = append(names, name)
+		}
+	}
+	dedups := make(map[string]*deduplicator)
+	for _, name := range names {
+		lvl, ok := levels[name]
+		if !ok {
+			return nil, fmt.Errorf("logman has no level '%v'", name)
+		}
+		if lvl.osExit {
+			continue
+		}
+		dedups[name] = &deduplicator{level: name, by: opt.dedup.by, timeout: opt.dedup.timeout}
+	}
+	return dedups, nil
+}
+
+// deduplicator remembers last message written on level and counts its repeats.
+type deduplicator struct {
+	level   string
+	by      DedupKey
+	timeout time.Duration
+
+	mu      sync.Mutex
+	lastKey string
+	last    bool
+	lvl     *loggingLevel
+	repeats int
+	timer   *time.Timer
+}
+
+func (d *deduplicator) key(msg Message) string {
+	if d.by == DedupTemplate {
+		return messageTemplate(msg)
+	}
+	if m, ok := msg.(*message); ok {
+		return m.text()
+	}
+	return fmt.Sprint(msg.Value(keyMessage))
+}
+
+// pass reports whether message must be written. Repeats of last message are counted,
+// different message first reports repeats of previous one.
+func (d *deduplicator) pass(l *Logger, lvl *loggingLevel, writers *writerRegistry, msg Message) bool {
+	key := d.key(msg)
+	d.mu.Lock()
+	if d.last && d.lastKey == key {
+		d.repeats++
+		if d.timeout > 0 {
+			switch d.timer {
+			case nil:
+				d.timer = time.AfterFunc(d.timeout, func() { d.expire(l) })
+			default:
+				d.timer.Reset(d.timeout)
+			}
+		}
+		d.mu.Unlock()
+		return false
+	}
+	summary, summaryLevel := d.takeLocked()
+	d.last, d.lastKey, d.lvl = true, key, lvl
+	d.mu.Unlock()
+	if err := l.deliverSummary(summaryLevel, writers, summary); err != nil {
+		fmt.Fprintf(os.Stderr, "logman: %v\n", err)
+	}
+	return true
+}
+
+// expire reports repeats after timeout using current writers of l.
+func (d *deduplicator) expire(l *Logger) {
+	snap := l.acquire()
+	defer snap.epoch.release()
+	if err := d.flush(l, snap.epoch.writers); err != nil {
+		fmt.Fprintf(os.Stderr, "logman: %v\n", err)
+	}
+}
+
+// flush reports repeats of last message and forgets it.
+func (d *deduplicator) flush(l *Logger, writers *writerRegistry) error {
+	d.mu.Lock()
+	summary, lvl := d.takeLocked()
+	d.mu.Unlock()
+	return l.deliverSummary(lvl, writers, summary)
+}
+
+// takeLocked forgets last message and returns summary of its repeats with level
+// it was written on. Summary is nil if message was not repeated.
+func (d *deduplicator) takeLocked() (Message, *loggingLevel) {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	repeats, lvl := d.repeats, d.lvl
+	d.last, d.lastKey, d.lvl, d.repeats = false, "", nil, 0
+	if repeats == 0 || lvl == nil {
+		return nil, nil
+	}
+	summary := NewMessage("... repeated %v times", repeats)
+	summary.SetField(keyRepeated, repeats)
+	summary.SetField(keyLevel, lvl.tagField())
+	return summary, lvl
+}
+
+// flushDedups reports repeats of all deduplicators of snapshot.
+func flushDedups(l *Logger, snap *snapshot) {
+	for _, d := range snap.dedups {
+		if err := d.flush(l, snap.epoch.writers); err != nil {
+			fmt.Fprintf(os.Stderr, "logman: %v\n", err)
+		}
+	}
+}
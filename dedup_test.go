@@ -0,0 +1,85 @@
+package logman
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	for _, tc := range []struct {
+		by       DedupKey
+		repeats  int
+		expected []string
+	}{
+		{DedupMessage, 3, []string{"a", "... repeated 2 times", "b", "... repeated 1 times", "n 1", "n 2", "x", "... repeated 1 times", "x", "warned", "... repeated 1 times"}},
+		{DedupTemplate, 4, []string{"a", "... repeated 2 times", "b", "... repeated 1 times", "n 1", "... repeated 1 times", "x", "... repeated 1 times", "x", "warned", "... repeated 1 times"}},
+	} {
+		buf := &lockedBuffer{}
+		formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+		lgr, err := New(
+			WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(WARN, LevelImportance(ImportanceWARN))),
+			WithWriterNamed("buffer", buf, formatter),
+			WithDedup(tc.by, 20*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, text := range []string{"a", "a", "a", "b", "b"} {
+			lgr.Info(text)
+		}
+		lgr.Info("n %v", 1)
+		lgr.Info("n %v", 2)
+		lgr.Info("x")
+		lgr.Info("x")
+		deadline := time.Now().Add(5 * time.Second)
+		for strings.Count(buf.String(), "repeated") < tc.repeats {
+			if time.Now().After(deadline) {
+				t.Fatal("repeats are not reported after timeout")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		lgr.Info("x")
+		lgr.Warn("warned")
+		lgr.Warn("warned")
+		if err := lgr.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(buf.String(), " \n", "\n")), "\n")
+		if strings.Join(lines, "|") != strings.Join(tc.expected, "|") {
+			t.Errorf("dedup by %v: expected %q, have %q", tc.by, tc.expected, lines)
+		}
+	}
+}
+
+func TestDedupSummaryHooks(t *testing.T) {
+	buf := &lockedBuffer{}
+	hooked := []string{}
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO)),
+		WithWriterNamed("buffer", buf, NewFormatter(WithRequestedFields(Request_MessageOnly))),
+		WithDedup(DedupMessage, 0),
+		WithHooks(HookFunc(func(_ string, msg Message) Message {
+			hooked = append(hooked, fmt.Sprint(msg.Value(keyMessage)))
+			return msg
+		})),
+		WithRedaction(RedactDetectors(), RedactFields(), RedactPatterns(regexp.MustCompile(`repeated \d+`))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgr.Info("a")
+	lgr.Info("a")
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "a \n... [REDACTED] times \n" {
+		t.Errorf("summary is not redacted: %q", buf.String())
+	}
+	if len(hooked) != 3 || hooked[2] != "... repeated 1 times" {
+		t.Errorf("summary is not passed to hooks: %q", hooked)
+	}
+}
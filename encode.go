@@ -0,0 +1,244 @@
+package logman
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxPooledBuffer limits capacity of buffers returned to pool.
+const maxPooledBuffer = 64 << 10
+
+// buffer is byte slice reused for formatting messages.
+// Destinations must not retain text written to them.
+type buffer struct {
+	b []byte
+}
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return &buffer{b: make([]byte, 0, 512)}
+	},
+}
+
+func getBuffer() *buffer {
+	return bufferPool.Get().(*buffer)
+}
+
+func putBuffer(buf *buffer) {
+	if cap(buf.b) > maxPooledBuffer {
+		return
+	}
+	buf.b = buf.b[:0]
+	bufferPool.Put(buf)
+}
+
+// appendJSON appends JSON encoding of value. Values of field types and common types
+// are encoded without reflection, others with encoding/json.
+func appendJSON(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return appendJSONString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float32:
+		return appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		return appendJSONFloat(buf, v, 64)
+	case time.Duration:
+		return appendJSONString(buf, v.String())
+	case time.Time:
+		return appendJSONString(buf, v.Format(time.RFC3339Nano))
+	case ObjectValue:
+		buf = append(buf, '{')
+		for i, fld := range v {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, fld.key)
+			buf = append(buf, ':')
+			buf = appendJSON(buf, fld.value)
+		}
+		return append(buf, '}')
+	case ArrayValue:
+		return appendJSONArray(buf, v)
+	case []interface{}:
+		return appendJSONArray(buf, v)
+	case json.Marshaler:
+		return appendMarshaled(buf, v)
+	case *ErrorValue:
+		return appendJSONErrorValue(buf, v)
+	case error:
+		return appendJSONMethod(buf, value, v.Error, "Error")
+	case fmt.Stringer:
+		return appendJSONMethod(buf, value, v.String, "String")
+	}
+	return appendMarshaled(buf, value)
+}
+
+func appendJSONArray(buf []byte, values []interface{}) []byte {
+	buf = append(buf, '[')
+	for i, value := range values {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendJSON(buf, value)
+	}
+	return append(buf, ']')
+}
+
+// appendJSONFloat encodes NaN and infinities, which JSON has no numbers for, as strings.
+func appendJSONFloat(buf []byte, f float64, bits int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	buf = strconv.AppendFloat(buf, f, format, -1, bits)
+	if n := len(buf); format == 'e' && n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+		buf[n-2] = buf[n-1]
+		buf = buf[:n-1]
+	}
+	return buf
+}
+
+func appendMarshaled(buf []byte, value interface{}) []byte {
+	bt, err := json.Marshal(value)
+	if err != nil {
+		return appendJSONString(buf, fmt.Sprint(value))
+	}
+	return append(buf, bt...)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends quoted string escaped as encoding/json does.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// appendText appends plain text of value. Values of field types and common types
+// are written without reflection, others with fmt.
+func appendText(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, "<nil>"...)
+	case string:
+		return append(buf, v...)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float64:
+		return strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case time.Time:
+		return v.AppendFormat(buf, time.RFC3339Nano)
+	case error:
+		text, _ := methodText(value, v.Error, "Error")
+		return append(buf, text...)
+	case fmt.Stringer:
+		text, _ := methodText(value, v.String, "String")
+		return append(buf, text...)
+	}
+	return fmt.Append(buf, value)
+}
+
+// appendJSONMethod appends text returned by method, nil pointer receivers are encoded as null.
+func appendJSONMethod(buf []byte, value interface{}, method func() string, name string) []byte {
+	text, ok := methodText(value, method, name)
+	if !ok {
+		return append(buf, "null"...)
+	}
+	return appendJSONString(buf, text)
+}
+
+// methodText calls Error or String method of value recovering from panics as fmt does:
+// nil pointer receiver is "<nil>" (and false is returned), other panics are reported in text.
+func methodText(value interface{}, method func() string, name string) (text string, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if v := reflect.ValueOf(value); v.Kind() == reflect.Pointer && v.IsNil() {
+				text, ok = "<nil>", false
+				return
+			}
+			text, ok = fmt.Sprintf("%%!v(PANIC=%v method: %v)", name, r), true
+		}
+	}()
+	return method(), true
+}
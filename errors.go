@@ -0,0 +1,259 @@
+package logman
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Galdoba/logman/colorizer"
+)
+
+// maxErrorDepth limits depth of wrap chain walked, so cyclic chains can not hang formatters.
+const maxErrorDepth = 32
+
+// maxStackDepth limits number of frames captured for stack trace.
+const maxStackDepth = 32
+
+// ErrorValue is value of error field. It keeps error and, optionally, stack trace
+// captured where field was created. Wrap chain of error is walked when it is formatted.
+type ErrorValue struct {
+	err      error
+	stack    []uintptr
+	redacted *ErrorNode // wrap chain redacted by Redactor
+}
+
+// ErrorNode is error of wrap chain with its concrete type and errors it wraps.
+type ErrorNode struct {
+	Message string
+	Type    string
+	Causes  []ErrorNode
+}
+
+// Err - field "error" with error value. Formatters render its whole wrap chain.
+func Err(err error) messageField {
+	return messageField{keyError, newErrorValue(err, 0, false)}
+}
+
+// ErrStack - field "error" with error value and stack trace of the caller.
+func ErrStack(err error) messageField {
+	return messageField{keyError, newErrorValue(err, 1, true)}
+}
+
+// WithErrorStack - Error and Errorf capture stack trace of the call site into field "error".
+func WithErrorStack() LogmanOptions {
+	return func(o *options) {
+		o.errorStack = true
+	}
+}
+
+// newErrorValue captures stack above skip frames of its caller if withStack is set.
+func newErrorValue(err error, skip int, withStack bool) *ErrorValue {
+	ev := &ErrorValue{err: err}
+	if withStack {
+		pcs := make([]uintptr, maxStackDepth)
+		ev.stack = pcs[:runtime.Callers(skip+2, pcs)]
+	}
+	return ev
+}
+
+// Error returns text of error.
+func (e *ErrorValue) Error() string {
+	if e.redacted != nil {
+		return e.redacted.Message
+	}
+	if e.err == nil {
+		return "<nil>"
+	}
+	text, _ := methodText(e.err, e.err.Error, "Error")
+	return text
+}
+
+// Unwrap returns error kept, so errors.Is and errors.As see through field value.
+func (e *ErrorValue) Unwrap() error {
+	return e.err
+}
+
+// Tree returns error with errors it wraps, joined errors become siblings.
+func (e *ErrorValue) Tree() ErrorNode {
+	if e.redacted != nil {
+		return *e.redacted
+	}
+	return errorTree(e.err, 0)
+}
+
+// Stack returns frames captured with error, nil if stack was not captured.
+func (e *ErrorValue) Stack() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(e.stack)
+	stack := []runtime.Frame{}
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame)
+		if !more {
+			return stack
+		}
+	}
+}
+
+func errorTree(err error, depth int) ErrorNode {
+	if err == nil {
+		return ErrorNode{Message: "<nil>", Type: "<nil>"}
+	}
+	text, _ := methodText(err, err.Error, "Error")
+	node := ErrorNode{Message: text, Type: fmt.Sprintf("%T", err)}
+	if depth >= maxErrorDepth {
+		return node
+	}
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, cause := range wrapper.Unwrap() {
+			if cause != nil {
+				node.Causes = append(node.Causes, errorTree(cause, depth+1))
+			}
+		}
+	case interface{ Unwrap() error }:
+		if cause := wrapper.Unwrap(); cause != nil {
+			node.Causes = append(node.Causes, errorTree(cause, depth+1))
+		}
+	}
+	return node
+}
+
+// appendErrorTree appends error chain as indented lines like:
+//
+//	*fmt.wrapError: read config: no such file
+//	  *fs.PathError: open config.yaml: no such file
+//	    syscall.Errno: no such file
+//
+// Lines of multiline messages (like joined errors) are indented under first one.
+func appendErrorTree(buf []byte, node ErrorNode, indent int, colors Colorizer) []byte {
+	pad := strings.Repeat("  ", indent)
+	text := strings.ReplaceAll(node.Message, "\n", "\n"+pad+"  ")
+	buf = append(buf, '\n')
+	buf = append(buf, pad...)
+	switch colors {
+	case nil:
+		buf = append(buf, node.Type...)
+		buf = append(buf, ": "...)
+		buf = append(buf, text...)
+	default:
+		buf = append(buf, colors.ColorizeByKeys(node.Type, colorizer.NewKey(colorizer.FG_KEY, "caller"), colorizer.NewKey(colorizer.BG_KEY, "caller"))...)
+		buf = append(buf, ": "...)
+		buf = append(buf, colors.ColorizeByKeys(text, colorizer.NewKey(colorizer.FG_KEY, "error"), colorizer.NewKey(colorizer.BG_KEY, "error"))...)
+	}
+	for _, cause := range node.Causes {
+		buf = appendErrorTree(buf, cause, indent+1, colors)
+	}
+	return buf
+}
+
+func appendStack(buf []byte, stack []runtime.Frame, indent int) []byte {
+	for _, frame := range stack {
+		buf = append(buf, '\n')
+		buf = append(buf, strings.Repeat("  ", indent)...)
+		buf = append(buf, frame.Function...)
+		buf = append(buf, '\n')
+		buf = append(buf, strings.Repeat("  ", indent+1)...)
+		buf = append(buf, frame.File...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(frame.Line), 10)
+	}
+	return buf
+}
+
+// appendJSONError appends error as object with wrapped errors in nested array "causes".
+func appendJSONError(buf []byte, node ErrorNode) []byte {
+	buf = append(buf, `{"message":`...)
+	buf = appendJSONString(buf, node.Message)
+	buf = append(buf, `,"type":`...)
+	buf = appendJSONString(buf, node.Type)
+	if len(node.Causes) > 0 {
+		buf = append(buf, `,"causes":[`...)
+		for i, cause := range node.Causes {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONError(buf, cause)
+		}
+		buf = append(buf, ']')
+	}
+	return append(buf, '}')
+}
+
+func appendJSONErrorValue(buf []byte, ev *ErrorValue) []byte {
+	buf = appendJSONError(buf, ev.Tree())
+	stack := ev.Stack()
+	if len(stack) == 0 {
+		return buf
+	}
+	buf = append(buf[:len(buf)-1], `,"stack":[`...)
+	for i, frame := range stack {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, `{"func":`...)
+		buf = appendJSONString(buf, frame.Function)
+		buf = append(buf, `,"file":`...)
+		buf = appendJSONString(buf, frame.File)
+		buf = append(buf, `,"line":`...)
+		buf = strconv.AppendInt(buf, int64(frame.Line), 10)
+		buf = append(buf, '}')
+	}
+	return append(buf, "]}"...)
+}
+
+// stdFormatError renders field "error" as indented tree of wrap chain followed by stack trace.
+// Errors which are not ErrorValue are rendered the same way. Messages without error render nothing.
+func stdFormatError(msg Message, colors Colorizer) (string, error) {
+	var ev *ErrorValue
+	switch v := msg.Value(keyError).(type) {
+	case nil:
+		return "", nil
+	case *ErrorValue:
+		ev = v
+	case error:
+		ev = &ErrorValue{err: v}
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+	buf := appendErrorTree(nil, ev.Tree(), 1, colors)
+	if stack := ev.Stack(); len(stack) > 0 {
+		buf = append(buf, "\n  stack:"...)
+		buf = appendStack(buf, stack, 2)
+	}
+	return string(buf), nil
+}
+
+// wrapVerbsAsV replaces verbs %w of format with %v, so message renders
+// wrapped errors as fmt.Errorf does.
+func wrapVerbsAsV(format string) string {
+	var rewritten []byte
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(format) && strings.IndexByte("#0+- .*[]0123456789", format[j]) >= 0 {
+			j++
+		}
+		if j < len(format) && format[j] == 'w' {
+			if rewritten == nil {
+				rewritten = []byte(format)
+			}
+			rewritten[j] = 'v'
+		}
+		i = j
+	}
+	if rewritten == nil {
+		return format
+	}
+	return string(rewritten)
+}
+
+// escapeFormat makes text safe to use as format of message.
+func escapeFormat(text string) string {
+	return strings.ReplaceAll(text, "%", "%%")
+}
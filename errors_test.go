@@ -0,0 +1,116 @@
+package logman
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestErrorValue(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "config.yaml", Err: fs.ErrNotExist}
+	err := fmt.Errorf("load: %w", errors.Join(pathErr, errors.New("fallback failed")))
+	ev := Err(err).Value().(*ErrorValue)
+	if !errors.Is(ev, fs.ErrNotExist) {
+		t.Errorf("wrapped error is not found through field value")
+	}
+	tree := ev.Tree()
+	if tree.Type != "*fmt.wrapError" || len(tree.Causes) != 1 {
+		t.Fatalf("unexpected root %+v", tree)
+	}
+	joined := tree.Causes[0]
+	if joined.Type != "*errors.joinError" || len(joined.Causes) != 2 {
+		t.Fatalf("unexpected joined error %+v", joined)
+	}
+	if joined.Causes[0].Type != "*fs.PathError" || joined.Causes[0].Causes[0].Message != "file does not exist" {
+		t.Errorf("unexpected path error %+v", joined.Causes[0])
+	}
+	if ev.Stack() != nil {
+		t.Errorf("stack is captured without request")
+	}
+
+	encoded := appendJSON(nil, ev)
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("invalid JSON %s: %v", encoded, err)
+	}
+	causes := decoded["causes"].([]interface{})[0].(map[string]interface{})["causes"].([]interface{})
+	if len(causes) != 2 || causes[1].(map[string]interface{})["message"] != "fallback failed" {
+		t.Errorf("unexpected causes in %s", encoded)
+	}
+
+	msg := NewMessage("failed").WithFields(Err(err))
+	text, _ := stdFormatError(msg, nil)
+	lines := strings.Split(text, "\n")
+	expected := []string{
+		"",
+		"  *fmt.wrapError: load: open config.yaml: file does not exist",
+		"    fallback failed",
+		"    *errors.joinError: open config.yaml: file does not exist",
+		"      fallback failed",
+		"      *fs.PathError: open config.yaml: file does not exist",
+		"        *errors.errorString: file does not exist",
+		"      *errors.errorString: fallback failed",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("unexpected tree:%v", text)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %v: expected %q, got %q", i, expected[i], lines[i])
+		}
+	}
+	if text, _ := stdFormatError(NewMessage("no error"), nil); text != "" {
+		t.Errorf("message without error is rendered as %q", text)
+	}
+}
+
+func TestErrorfWraps(t *testing.T) {
+	buf := &lockedBuffer{}
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(ERROR, LevelImportance(ImportanceERROR))),
+		WithWriterNamed("buffer", buf, NewFormatter(WithRequestedFields([]string{keyMessage, keyError}))),
+		WithErrorStack(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sentinel := errors.New("disk full")
+	created := lgr.Errorf("save %v: %w", "report", sentinel)
+	if !errors.Is(created, sentinel) {
+		t.Errorf("Errorf does not wrap %%w operand")
+	}
+	if err := lgr.Error(errors.New("100% broken")); err == nil {
+		t.Errorf("input error is not returned")
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"save report: disk full \n  *fmt.wrapError: save report: disk full\n    *errors.errorString: disk full\n  stack:\n    github.com/Galdoba/logman.TestErrorfWraps\n",
+		"100% broken \n  *errors.errorString: 100% broken\n  stack:\n    github.com/Galdoba/logman.TestErrorfWraps\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%v", want, out)
+		}
+	}
+	if strings.Contains(out, "%!w") {
+		t.Errorf("%%w is not rendered:\n%v", out)
+	}
+	if ev := ErrStack(sentinel).Value().(*ErrorValue); ev.Stack()[0].Function != "github.com/Galdoba/logman.TestErrorfWraps" {
+		t.Errorf("stack does not start at caller: %v", ev.Stack()[0].Function)
+	}
+}
+
+func TestWrapVerbsAsV(t *testing.T) {
+	for format, want := range map[string]string{
+		"load %s: %w":      "load %s: %v",
+		"100%% %w %[1]-4w": "100%% %v %[1]-4v",
+		"no verbs":         "no verbs",
+		"%%w":              "%%w",
+	} {
+		if got := wrapVerbsAsV(format); got != want {
+			t.Errorf("%q: expected %q, got %q", format, want, got)
+		}
+	}
+}
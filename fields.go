@@ -0,0 +1,110 @@
+package logman
+
+import (
+	"strings"
+	"time"
+)
+
+const keyError = "error"
+
+// Key returns key of field.
+func (f messageField) Key() string {
+	return f.key
+}
+
+// Value returns value of field.
+func (f messageField) Value() interface{} {
+	return f.value
+}
+
+// ObjectValue is value of field created by Object. Fields keep their order.
+type ObjectValue []messageField
+
+// ArrayValue is value of field created by Array.
+type ArrayValue []interface{}
+
+// String - field with string value.
+func String(key, value string) messageField {
+	return messageField{key, value}
+}
+
+// Int - field with int value.
+func Int(key string, value int) messageField {
+	return messageField{key, value}
+}
+
+// Float - field with float64 value.
+func Float(key string, value float64) messageField {
+	return messageField{key, value}
+}
+
+// Bool - field with bool value.
+func Bool(key string, value bool) messageField {
+	return messageField{key, value}
+}
+
+// Duration - field with time.Duration value.
+func Duration(key string, value time.Duration) messageField {
+	return messageField{key, value}
+}
+
+// Time - field with time.Time value.
+func Time(key string, value time.Time) messageField {
+	return messageField{key, value}
+}
+
+// Any - field with value of any type. Values of types not known to formatters
+// are encoded with reflection.
+func Any(key string, value interface{}) messageField {
+	return messageField{key, value}
+}
+
+// Object - field with nested object made of fields.
+func Object(key string, fields ...messageField) messageField {
+	return messageField{key, ObjectValue(fields)}
+}
+
+// Array - field with array of values. Values keep their types.
+func Array(key string, values ...interface{}) messageField {
+	return messageField{key, ArrayValue(values)}
+}
+
+// String returns text of object like {key=value key=value}.
+func (o ObjectValue) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("{")
+	for i, fld := range o {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(fld.key)
+		sb.WriteString("=")
+		sb.Write(appendText(nil, fld.value))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// ColorKind returns "struct", so colorizer paints object as struct.
+func (o ObjectValue) ColorKind() string {
+	return "struct"
+}
+
+// String returns text of array like [value value].
+func (a ArrayValue) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("[")
+	for i, value := range a {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.Write(appendText(nil, value))
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// ColorKind returns "slice", so colorizer paints array as slice.
+func (a ArrayValue) ColorKind() string {
+	return "slice"
+}
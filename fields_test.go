@@ -0,0 +1,136 @@
+package logman
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Galdoba/logman/colorizer"
+)
+
+func TestTypedFieldsJSON(t *testing.T) {
+	buf := &lockedBuffer{}
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(ERROR, LevelImportance(ImportanceERROR))),
+		WithWriterNamed("json", buf, NewFormatter(WithCustomFunc("json", stdJSON), WithRequestedFields([]string{"json"}))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamp := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	msg := NewMessage("failed %v", 3).WithFields(
+		String("name", "a<b>"),
+		Int("count", 42),
+		Float("ratio", 0.5),
+		Bool("ok", false),
+		Duration("took", 1500*time.Millisecond),
+		Time("at", stamp),
+		Err(errors.New("boom")),
+		Any("tags", map[string]int{"x": 1}),
+		Object("user", String("id", "u1"), Int("age", 30), Object("geo", Float("lat", 1.25))),
+		Array("list", 1, "two", true, ObjectValue{Int("n", 3)}),
+	)
+	if err := lgr.ProcessMessage(msg, ERROR); err != nil {
+		t.Fatal(err)
+	}
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &decoded); err != nil {
+		t.Fatalf("invalid json %v: %v", buf.String(), err)
+	}
+	keys, _ := decoded["logman keys"].(map[string]interface{})
+	expected := `{"age":30,"geo":{"lat":1.25},"id":"u1"}`
+	for key, want := range map[string]string{
+		"name":  `"a\u003cb\u003e"`,
+		"count": `42`,
+		"ratio": `0.5`,
+		"ok":    `false`,
+		"took":  `"1.5s"`,
+		"at":    `"2024-05-06T07:08:09Z"`,
+		"error": `{"message":"boom","type":"*errors.errorString"}`,
+		"tags":  `{"x":1}`,
+		"user":  expected,
+		"list":  `[1,"two",true,{"n":3}]`,
+	} {
+		have, err := json.Marshal(keys[key])
+		if err != nil || string(have) != want {
+			t.Errorf("field %v: expected %v, have %s (%v)", key, want, have, err)
+		}
+	}
+	if !strings.Contains(buf.String(), `"user":{"id":"u1","age":30,"geo":{"lat":1.25}}`) {
+		t.Errorf("object fields lost their order: %v", buf.String())
+	}
+}
+
+func TestAppendJSON(t *testing.T) {
+	for _, value := range []interface{}{
+		"plain", "quote\" slash\\ <tag> & \n\t\x01   юникод \xff",
+		int8(-3), uint64(1 << 63), float32(0.1), 1e21, 1e-7, -0.0, 123456789.125,
+		[]interface{}{nil, "a"}, struct{ A int }{1},
+	} {
+		want, _ := json.Marshal(value)
+		if have := appendJSON(nil, value); string(have) != string(want) {
+			t.Errorf("encode %#v: expected %s, have %s", value, want, have)
+		}
+	}
+}
+
+func TestColorizeTyped(t *testing.T) {
+	scheme := colorizer.DefaultScheme()
+	for value, text := range map[interface{}]string{
+		"text":            "text",
+		42:                "42",
+		time.Second:       "1s",
+		errors.New("bad"): "bad",
+	} {
+		if colored := scheme.ColorizeByType(value); !strings.Contains(colored, text) {
+			t.Errorf("colorize %#v: expected %q in %q", value, text, colored)
+		}
+	}
+	obj := ObjectValue{String("a", "x"), Int("b", 2)}
+	if colored := scheme.ColorizeByType(obj); !strings.Contains(colored, "{a=x b=2}") {
+		t.Errorf("colorize object: have %q", colored)
+	}
+}
+
+type nilErr struct{ text string }
+
+func (e *nilErr) Error() string { return e.text }
+
+type panicStringer struct{}
+
+func (panicStringer) String() string { panic("broken") }
+
+func TestNilReceivers(t *testing.T) {
+	var err *nilErr
+	cases := []struct {
+		value      interface{}
+		json, text string
+	}{
+		{err, `null`, "<nil>"},
+		{panicStringer{}, `"%!v(PANIC=String method: broken)"`, "%!v(PANIC=String method: broken)"},
+	}
+	for _, c := range cases {
+		if have := string(appendJSON(nil, c.value)); have != c.json {
+			t.Errorf("JSON of %T: expected %v, have %v", c.value, c.json, have)
+		}
+		if have := string(appendText(nil, c.value)); have != c.text {
+			t.Errorf("text of %T: expected %v, have %v", c.value, c.text, have)
+		}
+	}
+	if have := colorizer.DefaultScheme().ColorizeByType(err); !strings.Contains(have, "<nil>") {
+		t.Errorf("colorized nil error: have %q", have)
+	}
+	msg := NewMessage("nil").WithFields(Any("e", err), Err(err))
+	if _, err := stdJSON(msg, nil); err != nil {
+		t.Error(err)
+	}
+	if text, _ := stdFormatError(msg, nil); !strings.Contains(text, "*logman.nilErr: <nil>") {
+		t.Errorf("unexpected error tree %q", text)
+	}
+}
@@ -0,0 +1,68 @@
+package logman
+
+import "fmt"
+
+// writerFilter decides whether message processed on level is written to writer.
+type writerFilter struct {
+	minimum    int
+	predicates []func(Message) bool
+}
+
+// WriterOption - settings of writer attached to level.
+type WriterOption func(*writerFilter)
+
+// WriterMinImportance sets importance floor of writer: messages of levels
+// less important than minimum are not written to it.
+func WriterMinImportance(minimum int) WriterOption {
+	return func(wf *writerFilter) {
+		wf.minimum = minimum
+	}
+}
+
+// WriterMatch adds predicate over message. Message is written to writer only
+// if all predicates return true. Predicate must not modify message.
+func WriterMatch(match func(Message) bool) WriterOption {
+	return func(wf *writerFilter) {
+		if match != nil {
+			wf.predicates = append(wf.predicates, match)
+		}
+	}
+}
+
+// WriterFieldEquals adds predicate passing messages which field key is printed as value.
+func WriterFieldEquals(key, value string) WriterOption {
+	return WriterMatch(func(msg Message) bool {
+		found := msg.Value(key)
+		return found != nil && fmt.Sprint(found) == value
+	})
+}
+
+// newWriterFilter returns nil if options set no filtering.
+func newWriterFilter(opts ...WriterOption) *writerFilter {
+	if len(opts) == 0 {
+		return nil
+	}
+	wf := writerFilter{}
+	for _, set := range opts {
+		set(&wf)
+	}
+	if wf.minimum <= ImportanceALL && len(wf.predicates) == 0 {
+		return nil
+	}
+	return &wf
+}
+
+func (wf *writerFilter) allow(lvl *loggingLevel, msg Message) bool {
+	if wf == nil {
+		return true
+	}
+	if lvl.importance < wf.minimum {
+		return false
+	}
+	for _, match := range wf.predicates {
+		if !match(msg) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package logman
+
+import (
+	"testing"
+)
+
+func TestWriterFilters(t *testing.T) {
+	console, all, billing := &lockedBuffer{}, &lockedBuffer{}, &lockedBuffer{}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(
+			NewLoggingLevel(INFO),
+			NewLoggingLevel(WARN, LevelImportance(ImportanceWARN)),
+			NewLoggingLevel(TRACE, LevelImportance(ImportanceTRACE)),
+		),
+		WithWriterNamed("console", console, nil),
+		WithWriterNamed("all", all, nil),
+		WithWriterNamed("billing", billing, nil),
+		WithGlobalWriterFormatter("console", formatter, WriterMinImportance(ImportanceINFO)),
+		WithGlobalWriterFormatter("all", formatter),
+		WithGlobalWriterFormatter("billing", formatter, WriterFieldEquals("component", "billing")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgr.Trace(NewMessage("traced"))
+	lgr.Info("started")
+	lgr.With(NewField("component", "billing")).Warn("charged")
+	lgr.With(NewField("component", "auth")).Warn("denied")
+
+	for name, tc := range map[string]struct {
+		buf      *lockedBuffer
+		expected string
+	}{
+		"console": {console, "started \ncharged \ndenied \n"},
+		"all":     {all, "traced \nstarted \ncharged \ndenied \n"},
+		"billing": {billing, "charged \n"},
+	} {
+		if tc.buf.String() != tc.expected {
+			t.Errorf("%v: expected %q, have %q", name, tc.expected, tc.buf.String())
+		}
+	}
+
+	if err := lgr.SetLevelWriterFormatter(WARN, "console", formatter, WriterMinImportance(ImportanceERROR)); err != nil {
+		t.Fatal(err)
+	}
+	if err := lgr.SetLevelWriterFormatter(WARN, "billing", formatter); err != nil {
+		t.Fatal(err)
+	}
+	lgr.Warn("after")
+	if console.String() != "started \ncharged \ndenied \n" || billing.String() != "charged \nafter \n" {
+		t.Errorf("filters are not replaced: console %q, billing %q", console.String(), billing.String())
+	}
+}
+
+func TestWriterFiltersConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+writers:
+  - key: audit.log
+    importance: warn
+    match: {component: billing, code: 42}
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wc := cfg.Writers[0]
+	if wc.Importance != WARN || wc.Match["component"] != "billing" || wc.Match["code"] != "42" {
+		t.Errorf("unexpected writer config %+v", wc)
+	}
+	if _, err := cfg.Options(); err != nil {
+		t.Fatal(err)
+	}
+}
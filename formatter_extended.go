@@ -1,8 +1,6 @@
 package logman
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -39,53 +37,53 @@ func basicFormatter(field string, val interface{}) (string, error) {
 }
 
 func (fe *formatterExpanded) Format(msg Message, color bool) string {
-	output := ""
-	for _, field := range fe.requestedFields {
-
-		val := msg.Value(field)
+	return string(fe.appendFormat(nil, msg, color))
+}
 
-		formatted := ""
-		err := errors.New("not formatted")
+// appendFormat appends formatted message to buf.
+func (fe *formatterExpanded) appendFormat(buf []byte, msg Message, color bool) []byte {
+	start := len(buf)
+	for _, field := range fe.requestedFields {
 		fn := fe.fieldFormaFuncMap[field]
 		switch fn {
 		case nil:
-			formatted, err = basicFormatter(field, val)
-			if err != nil {
-				return output + formatted
-			}
+			formatted, _ := basicFormatter(field, msg.Value(field))
+			buf = append(buf, formatted...)
 		default:
 			if field == "json" {
-				text, err := stdJSON(msg, nil)
+				jsonBuf, err := appendStdJSON(buf[:start], msg)
 				if err != nil {
-					return err.Error()
+					return append(buf[:start], err.Error()...)
 				}
-				return text
+				return jsonBuf
 			}
-			// if field == keyCallerLong {
-			// 	field = keyCaller
-			// }
-			// if field == keyCallerShort {
-			// 	field = keyCaller
-			// }
-
-			//if msg.Value(field) == nil {
-			//fmt.Println("no field", field)
-			//	continue
-			//}
-			switch color {
-			case true:
-				formatted, err = fn(msg, fe.colorizer)
-			case false:
-				formatted, err = fn(msg, nil)
+			colors := fe.colorizer
+			if !color {
+				colors = nil
 			}
+			formatted, err := fn(msg, colors)
+			buf = append(buf, formatted...)
 			if err != nil {
-				return output + formatted + "!<> " + err.Error()
+				buf = append(buf, "!<> "...)
+				return append(buf, err.Error()...)
 			}
 		}
-		output += formatted + " "
+		buf = append(buf, ' ')
+	}
+	return buf
+}
 
+func (fe *formatterExpanded) clone() *formatterExpanded {
+	if fe == nil {
+		return nil
+	}
+	cp := *fe
+	cp.fieldFormaFuncMap = make(map[string]func(Message, Colorizer) (string, error))
+	for k, fn := range fe.fieldFormaFuncMap {
+		cp.fieldFormaFuncMap[k] = fn
 	}
-	return output
+	cp.requestedFields = append([]string{}, fe.requestedFields...)
+	return &cp
 }
 
 func mustIgnore(fields []string, field string) bool {
@@ -120,6 +118,7 @@ func defaultFormatterOptions() formatterOptions {
 			keyMessage:     stdFormatMessage,
 			keyCallerShort: stdFormatCallerShort,
 			keyCallerLong:  stdFormatCallerLong,
+			keyError:       stdFormatError,
 		},
 		colorizer: nil,
 	}
@@ -149,6 +148,7 @@ var Request_ShortSince = []string{keySince, keyLevel, keyMessage}
 var Request_ShortReport = []string{keySince, keyMessage}
 var Request_Medium = []string{keyTime, keyLevel, keyMessage, keyCallerShort}
 var Request_Full = []string{keyTime, keySince, keyLevel, keyMessage, keyCallerLong}
+var Request_Error = []string{keyTime, keyLevel, keyMessage, keyError}
 
 func WithCustomFunc(requestKey string, fn func(Message, Colorizer) (string, error)) FormatterOption {
 	return func(fo *formatterOptions) {
@@ -157,9 +157,17 @@ func WithCustomFunc(requestKey string, fn func(Message, Colorizer) (string, erro
 }
 
 func stdFormatFunc_time(msg Message, colors Colorizer) (string, error) {
-	tm, err := validateTimeArg(msg.Value("time"))
-	if err != nil {
-		return "", err
+	tm, ok := createdAt(msg)
+	if !ok {
+		var err error
+		if tm, err = validateTimeArg(msg.Value(keyTime)); err != nil {
+			return "", err
+		}
+	}
+	if colors == nil {
+		var buf [32]byte
+		text := tm.AppendFormat(append(buf[:0], '['), timeLayout)
+		return string(append(text, ']')), nil
 	}
 	text := formatTime(tm)
 	// if len(text) < 21 {
@@ -174,27 +182,15 @@ func stdFormatFunc_time(msg Message, colors Colorizer) (string, error) {
 	return fmt.Sprintf("[%v]", text), nil
 }
 
+const timeLayout = "2006-01-02 15:04:05.000"
+
 func formatTime(tm time.Time) string {
-	s := tm.Format("2006-01-02 15:04:05.999")
-	slice := strings.Split(s, "")
-	switch len(slice) {
-	case 19:
-		s += ".000"
-	case 20:
-		s += "000"
-	case 21:
-		s += "00"
-	case 22:
-		s += "0"
-	case 23:
-		return s
-	}
-	return s
+	return tm.Format(timeLayout)
 }
 
 func stdFormatFunc_since(msg Message, colors Colorizer) (string, error) {
 
-	duration := time.Since(logMan.startTime)
+	duration := time.Since(loggerOf(msg).startTime)
 	//fmt.Println("to log", duration)
 	switch colors {
 	case nil:
@@ -215,6 +211,9 @@ func validateTimeArg(args ...any) (time.Time, error) {
 	if len(args) != 1 {
 		return time.Time{}, fmt.Errorf("stdTimeFormat function expect 1 argument (have %v)", len(args))
 	}
+	if tm, ok := args[0].(time.Time); ok {
+		return tm, nil
+	}
 	val := args[0]
 	str := fmt.Sprintf("%v", val)
 	str = strings.TrimPrefix(str, "[")
@@ -226,30 +225,34 @@ func validateTimeArg(args ...any) (time.Time, error) {
 	return tm, nil
 }
 
+// appendTimeText appends value of field "time" of message as RFC3339Nano text.
+func appendTimeText(buf []byte, msg Message) []byte {
+	if tm, ok := createdAt(msg); ok {
+		return tm.AppendFormat(buf, time.RFC3339Nano)
+	}
+	return fmt.Appendf(buf, "%v", msg.Value(keyTime))
+}
+
 func stdFormatMessage(msg Message, colors Colorizer) (string, error) {
+	m, ok := msg.(*message)
+	switch {
+	case ok && colors == nil:
+		return m.text(), nil
+	case ok && !m.ownText:
+		return colors.ColorizeByType(m.text()), nil
+	case ok:
+		return colorSprintf(colors, m.format, m.formatArgs), nil
+	}
 	inputs := msg.InputArgs()
-	var format string
-	var args []interface{}
-	for i := -1; i < len(inputs)-1; i++ {
-		switch i {
-		case -1:
-			format = fmt.Sprintf("%v", inputs[-1])
-		default:
-			args = append(args, inputs[i])
-		}
+	format := fmt.Sprintf("%v", inputs[-1])
+	args := make([]interface{}, 0, len(inputs))
+	for i := 0; i < len(inputs)-1; i++ {
+		args = append(args, inputs[i])
 	}
-	switch colors {
-	case nil:
+	if colors == nil {
 		return fmt.Sprintf(format, args...), nil
-	default:
-		var coloredArgs []string
-		for _, arg := range args {
-			colored := colors.ColorizeByType(arg)
-			coloredArgs = append(coloredArgs, colored)
-		}
-		text := combineColored(format, coloredArgs...)
-		return fmt.Sprintf(text), nil
 	}
+	return colorSprintf(colors, format, args), nil
 }
 
 func stdFormatLevel(msg Message, colors Colorizer) (string, error) {
@@ -317,55 +320,84 @@ func stdFormatCallerShort(msg Message, colors Colorizer) (string, error) {
 	}
 }
 
+// JSONlog is layout of message written by json formatter.
 type JSONlog struct {
-	APP   string            `json:"app"`
-	LVL   string            `json:"level"`
-	MSG   string            `json:"message"`
-	TIME  string            `json:"time"`
-	AGRS1 map[string]string `json:"logman keys,omitempty"`
-	AGRS2 map[string]string `json:"input arguments,omitempty"`
+	APP   string                 `json:"app"`
+	LVL   string                 `json:"level"`
+	MSG   string                 `json:"message"`
+	TIME  string                 `json:"time"`
+	AGRS1 map[string]interface{} `json:"logman keys,omitempty"`
+	AGRS2 map[string]interface{} `json:"input arguments,omitempty"`
 }
 
+// stdJSON encodes message as JSONlog. Fields of message are written on every level,
+// input arguments on levels FATAL and TRACE. Field values and input arguments keep
+// their types: numbers, booleans, objects and arrays are written as such.
 func stdJSON(msg Message, color Colorizer) (string, error) {
-	jsMsg := JSONlog{}
-	jsMsg.APP = logMan.appName
-	jsMsg.TIME = fmt.Sprintf("%v", msg.Value(keyTime))
-	jsMsg.LVL = fmt.Sprintf("%v", msg.Value(keyLevel))
-	msgText, err := stdFormatMessage(msg, nil)
+	buf, err := appendStdJSON(make([]byte, 0, 256), msg)
 	if err != nil {
 		return "", err
 	}
-	jsMsg.MSG = msgText
-	keys := msg.Fields()
-	for _, key := range keys {
+	return string(buf), nil
+}
+
+func appendStdJSON(buf []byte, msg Message) ([]byte, error) {
+	level := fmt.Sprintf("%v", msg.Value(keyLevel))
+	msgText, err := stdFormatMessage(msg, nil)
+	if err != nil {
+		return buf, err
+	}
+	buf = append(buf, `{"app":`...)
+	buf = appendJSONString(buf, loggerOf(msg).appName)
+	buf = append(buf, `,"level":`...)
+	buf = appendJSONString(buf, level)
+	buf = append(buf, `,"message":`...)
+	buf = appendJSONString(buf, msgText)
+	buf = append(buf, `,"time":`...)
+	if tm, ok := createdAt(msg); ok {
+		buf = append(buf, '"')
+		buf = tm.AppendFormat(buf, time.RFC3339Nano)
+		buf = append(buf, '"')
+	} else {
+		buf = appendJSONString(buf, fmt.Sprintf("%v", msg.Value(keyTime)))
+	}
+	written := 0
+	for _, key := range msg.Fields() {
 		switch key {
 		case keyTime, keyLevel, keyMessage:
-		default:
-			switch jsMsg.LVL {
-			case ERROR, FATAL, DEBUG, TRACE:
-				if jsMsg.AGRS1 == nil {
-					jsMsg.AGRS1 = make(map[string]string)
-				}
-				jsMsg.AGRS1[key] = fmt.Sprintf("%v", msg.Value(key))
-			}
-
+			continue
 		}
+		buf = appendJSONMember(buf, "logman keys", written, key, msg.Value(key))
+		written++
 	}
-
-	inputArgs := msg.InputArgs()
-	switch jsMsg.LVL {
+	if written > 0 {
+		buf = append(buf, '}')
+	}
+	// input arguments are dumped only on levels used for debugging
+	switch level {
 	case FATAL, TRACE:
-		if len(inputArgs) > 0 {
-			jsMsg.AGRS2 = make(map[string]string)
-			for i := 0; i < len(inputArgs)-1; i++ {
-				jsMsg.AGRS2[fmt.Sprintf("arg[%v]", i)] = fmt.Sprintf("%v", inputArgs[i])
-
-			}
+		inputArgs := msg.InputArgs()
+		for i := 0; i < len(inputArgs)-1; i++ {
+			buf = appendJSONMember(buf, "input arguments", i, fmt.Sprintf("arg[%v]", i), inputArgs[i])
+		}
+		if len(inputArgs) > 1 {
+			buf = append(buf, '}')
 		}
 	}
-	bt, err := json.Marshal(&jsMsg)
-	if err != nil {
-		return "", err
+	return append(buf, '}'), nil
+}
+
+// appendJSONMember appends member of nested object named object opening it before first member.
+func appendJSONMember(buf []byte, object string, index int, key string, value interface{}) []byte {
+	switch index {
+	case 0:
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, object)
+		buf = append(buf, ":{"...)
+	default:
+		buf = append(buf, ',')
 	}
-	return string(bt), nil
+	buf = appendJSONString(buf, key)
+	buf = append(buf, ':')
+	return appendJSON(buf, value)
 }
@@ -2,7 +2,10 @@ module github.com/Galdoba/logman
 
 go 1.23.1
 
-require github.com/gookit/color v1.5.4
+require (
+	github.com/gookit/color v1.5.4
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/xo/terminfo v0.0.0-20210125001918-ca9a967f8778 // indirect
@@ -0,0 +1,87 @@
+package logman
+
+import "os"
+
+const (
+	keyHostname = "hostname"
+	keyPid      = "pid"
+)
+
+// Hook is called for every message before it is formatted for writers of level.
+// It can inspect message, enrich it with SetField, return another Message to be
+// written instead of it (on this level only) or return nil to veto writing on level.
+// Fields set on message are seen by following levels and hooks.
+// In async mode hooks run on pipeline workers. Message must not be retained after
+// hook returns: messages created by Logger are reused.
+type Hook interface {
+	Before(level string, msg Message) Message
+}
+
+// HookFunc is function implementing Hook.
+type HookFunc func(level string, msg Message) Message
+
+// Before calls f.
+func (f HookFunc) Before(level string, msg Message) Message {
+	return f(level, msg)
+}
+
+// WriteResult is outcome of writing message to one writer of level.
+type WriteResult struct {
+	Writer string
+	Err    error
+}
+
+// AfterHook is called after message is written to writers of level with result
+// of every writer message was written to. Message must not be modified or retained.
+type AfterHook interface {
+	After(level string, msg Message, results []WriteResult)
+}
+
+// AfterHookFunc is function implementing AfterHook.
+type AfterHookFunc func(level string, msg Message, results []WriteResult)
+
+// After calls f.
+func (f AfterHookFunc) After(level string, msg Message, results []WriteResult) {
+	f(level, msg, results)
+}
+
+// WithHooks - adds hooks called in order before message is formatted for writers of level.
+func WithHooks(hooks ...Hook) LogmanOptions {
+	return func(o *options) {
+		o.hooks = append(o.hooks, hooks...)
+	}
+}
+
+// WithAfterHooks - adds hooks called in order after message is written to writers of level.
+func WithAfterHooks(hooks ...AfterHook) LogmanOptions {
+	return func(o *options) {
+		o.afterHooks = append(o.afterHooks, hooks...)
+	}
+}
+
+// HostInfoHook returns Hook setting fields "hostname" and "pid" of every message.
+func HostInfoHook() Hook {
+	hostname, _ := os.Hostname()
+	pid := os.Getpid()
+	return HookFunc(func(_ string, msg Message) Message {
+		msg.SetField(keyHostname, hostname)
+		msg.SetField(keyPid, pid)
+		return msg
+	})
+}
+
+// runHooks passes message through hooks and redactor. It returns nil if message is vetoed.
+func (l *Logger) runHooks(level string, msg Message) Message {
+	for _, hook := range l.hooks {
+		if msg = hook.Before(level, msg); msg == nil {
+			return nil
+		}
+	}
+	return msg
+}
+
+func (l *Logger) runAfterHooks(level string, msg Message, results []WriteResult) {
+	for _, hook := range l.afterHooks {
+		hook.After(level, msg, results)
+	}
+}
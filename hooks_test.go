@@ -0,0 +1,79 @@
+package logman
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken")
+}
+
+func TestHooks(t *testing.T) {
+	buf := &lockedBuffer{}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	mu := sync.Mutex{}
+	failed, pids := 0, 0
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(WARN, LevelImportance(ImportanceWARN))),
+		WithWriterNamed("buffer", buf, formatter),
+		WithWriterNamed("broken", failingWriter{}, formatter),
+		WithHooks(
+			HostInfoHook(),
+			HookFunc(func(level string, msg Message) Message {
+				text := msg.Value(keyMessage).(string)
+				switch {
+				case strings.HasPrefix(text, "secret"):
+					return nil
+				case level == WARN:
+					return NewMessage("warning: %v", text)
+				}
+				return msg
+			}),
+		),
+		WithAfterHooks(AfterHookFunc(func(level string, msg Message, results []WriteResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			if msg.Value(keyPid) == os.Getpid() {
+				pids++
+			}
+			for _, result := range results {
+				if result.Err != nil {
+					if result.Writer != "broken" {
+						t.Errorf("unexpected error of writer '%v': %v", result.Writer, result.Err)
+					}
+					failed++
+				}
+			}
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lgr.Info("plain"); err == nil {
+		t.Errorf("error of broken writer is not reported")
+	}
+	if err := lgr.ProcessMessage(NewMessage("secret %v", 1), INFO); err != nil {
+		t.Errorf("vetoed message reported error: %v", err)
+	}
+	lgr.Warn("disk")
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(buf.String(), " \n", "\n")), "\n")
+	if strings.Join(lines, "|") != "plain|warning: disk" {
+		t.Errorf("unexpected output %q", lines)
+	}
+	if failed != 2 {
+		t.Errorf("expected 2 failed writes, have %v", failed)
+	}
+	if pids != 1 {
+		t.Errorf("expected enriched message in 1 after hook, have %v", pids)
+	}
+}
@@ -0,0 +1,151 @@
+package logman
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// LazyValue is value computed by function when message is written.
+// Function is called once per message (once in total if LazyValue is used
+// as argument of several messages). In async mode it is called on pipeline worker.
+type LazyValue struct {
+	fn    func() any
+	once  sync.Once
+	value any
+}
+
+// Lazy - field with value computed by fn only when message is about to be formatted by
+// at least one writer: hooks and writer filters see LazyValue.
+// Lazy fields bound to Logger (see With) or ctx are computed for every message.
+func Lazy(key string, fn func() any) messageField {
+	return messageField{key, &LazyValue{fn: fn}}
+}
+
+// LazyArg - message argument computed by fn only when text of message is rendered.
+func LazyArg(fn func() any) *LazyValue {
+	return &LazyValue{fn: fn}
+}
+
+// Resolve returns value computed by function.
+func (lv *LazyValue) Resolve() any {
+	lv.once.Do(func() {
+		lv.value = lv.fn()
+	})
+	return lv.value
+}
+
+// Format formats computed value with verb and flags used.
+func (lv *LazyValue) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), lv.Resolve())
+}
+
+// fresh returns value to be set on new message: lazy values are not shared between messages.
+func fresh(value interface{}) interface{} {
+	if lv, ok := value.(*LazyValue); ok {
+		return &LazyValue{fn: lv.fn}
+	}
+	return value
+}
+
+// resolveLazy replaces lazy fields of message with their values.
+func resolveLazy(msg Message) {
+	m, ok := msg.(*message)
+	if ok && !m.lazy {
+		return
+	}
+	for _, key := range msg.Fields() {
+		if key == keyMessage {
+			continue
+		}
+		if lv, ok := msg.Value(key).(*LazyValue); ok {
+			msg.SetField(key, lv.Resolve())
+		}
+	}
+	if ok {
+		m.lazy = false
+	}
+}
+
+// Enabled reports whether l processes messages of level: level exists, is enabled and its
+// importance is not below minimum importance (adjusted by verbosity rules of caller package).
+// If component rules are set, importance of the least important one is assumed.
+func (l *Logger) Enabled(level string) bool {
+	return l.enabled(nil, 1, level)
+}
+
+// EnabledCtx reports whether l processes messages of level with ctx (see Enabled).
+// Minimum importance override of ctx (see ContextWithImportance) is respected.
+func (l *Logger) EnabledCtx(ctx context.Context, level string) bool {
+	return l.enabled(ctx, 1, level)
+}
+
+// Enabled reports whether default Logger processes messages of level.
+func Enabled(level string) bool {
+	return Default().enabled(nil, 1, level)
+}
+
+func (l *Logger) enabled(ctx context.Context, depth int, level string) bool {
+	snap := l.active().state.Load()
+	lvl := snap.levels[level]
+	if lvl == nil || lvl.disabled {
+		return false
+	}
+	minimum := snap.minimum
+	if snap.rules != nil {
+		minimum = snap.rules.minimum(2+depth, nil, minimum)
+		for _, rule := range snap.rules.components {
+			minimum = min(minimum, rule.importance)
+		}
+	}
+	if importance, ok := ImportanceFromContext(ctx); ok {
+		minimum = importance
+	}
+	return lvl.importance >= minimum
+}
+
+// ProcessFunc calls build and processes message it returns only if at least one of levels
+// is enabled (see Enabled), so message is not constructed when it would be skipped.
+func (l *Logger) ProcessFunc(build func() Message, levels ...string) error {
+	return l.processFunc(1, build, levels...)
+}
+
+// ProcessFunc processes message built by build with default Logger if any of levels is enabled.
+func ProcessFunc(build func() Message, levels ...string) error {
+	return Default().processFunc(1, build, levels...)
+}
+
+func (l *Logger) processFunc(depth int, build func() Message, levels ...string) error {
+	for _, level := range levels {
+		if l.enabled(nil, depth+1, level) {
+			return l.process(depth+1, build(), levels...)
+		}
+	}
+	return nil
+}
+
+// Debugf formats message according to a format specifier and writes to output writers of Level DEBUG.
+// Message is not created if level DEBUG is not enabled.
+func (l *Logger) Debugf(format string, args ...interface{}) error {
+	return l.logf(1, DEBUG, format, args...)
+}
+
+// Tracef formats message according to a format specifier and writes to output writers of Level TRACE.
+// Message is not created if level TRACE is not enabled.
+func (l *Logger) Tracef(format string, args ...interface{}) error {
+	return l.logf(1, TRACE, format, args...)
+}
+
+// This is a convinience function for ProcessMessage.
+// Debugf formats message according to a format specifier and writes to output writers of Level DEBUG.
+// It returns message processing error encountered.
+func Debugf(format string, args ...interface{}) error {
+	return Default().logf(1, DEBUG, format, args...)
+}
+
+// This is a convinience function for ProcessMessage.
+// Tracef formats message according to a format specifier and writes to output writers of Level TRACE.
+// It returns message processing error encountered.
+func Tracef(format string, args ...interface{}) error {
+	return Default().logf(1, TRACE, format, args...)
+}
@@ -0,0 +1,104 @@
+package logman
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type countingStringer struct {
+	calls *atomic.Int32
+}
+
+func (cs countingStringer) String() string {
+	cs.calls.Add(1)
+	return "rendered"
+}
+
+func TestLazyEvaluation(t *testing.T) {
+	buf := &lockedBuffer{}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	values := []interface{}{}
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(DEBUG, LevelImportance(ImportanceDEBUG))),
+		WithWriterNamed("buffer", buf, formatter),
+		WithWriterNamed("copy", &lockedBuffer{}, formatter),
+		WithAfterHooks(AfterHookFunc(func(_ string, msg Message, _ []WriteResult) {
+			values = append(values, msg.Value("n"))
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgr.SetMinimumImportance(ImportanceINFO)
+	if !lgr.Enabled(INFO) || lgr.Enabled(DEBUG) || lgr.Enabled("unknown") {
+		t.Errorf("unexpected Enabled: info %v, debug %v, unknown %v", lgr.Enabled(INFO), lgr.Enabled(DEBUG), lgr.Enabled("unknown"))
+	}
+	if !lgr.EnabledCtx(ContextWithImportance(context.Background(), ImportanceDEBUG), DEBUG) {
+		t.Errorf("importance of context is ignored")
+	}
+
+	argCalls, fieldCalls, rendered := atomic.Int32{}, atomic.Int32{}, atomic.Int32{}
+	arg := func() any { argCalls.Add(1); return 7 }
+	bound := lgr.With(Lazy("n", func() any { return fieldCalls.Add(1) }))
+	bound.Debugf("skipped %v", LazyArg(arg))
+	bound.Debug(NewMessage("skipped %v", countingStringer{&rendered}))
+	if err := bound.ProcessFunc(func() Message {
+		t.Errorf("message of disabled level is built")
+		return NewMessage("skipped")
+	}, DEBUG); err != nil {
+		t.Fatal(err)
+	}
+	if argCalls.Load() != 0 || fieldCalls.Load() != 0 || rendered.Load() != 0 {
+		t.Errorf("values of skipped messages are computed: args %v, fields %v, rendered %v", argCalls.Load(), fieldCalls.Load(), rendered.Load())
+	}
+
+	bound.Info("value %03d", LazyArg(arg))
+	bound.Info("again")
+	if err := lgr.SetVerbosity("logman=debug"); err != nil {
+		t.Fatal(err)
+	}
+	if !lgr.Enabled(DEBUG) {
+		t.Errorf("verbosity rule of caller package is ignored")
+	}
+	bound.Debugf("debug")
+	if argCalls.Load() != 1 {
+		t.Errorf("lazy argument computed %v times", argCalls.Load())
+	}
+	if len(values) != 3 || values[0] != int32(1) || values[1] != int32(2) || values[2] != int32(3) {
+		t.Errorf("lazy bound field must be computed for every message, have %v", values)
+	}
+	if text := strings.ReplaceAll(buf.String(), " \n", "|"); text != "value 007|again|debug|" {
+		t.Errorf("unexpected output %q", text)
+	}
+}
+
+func TestLazySkippedByHooksAndFilters(t *testing.T) {
+	buf := &lockedBuffer{}
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO)),
+		WithWriterNamed("buffer", buf, nil),
+		WithGlobalWriterFormatter("buffer", NewFormatter(WithRequestedFields(Request_MessageOnly)), WriterFieldEquals("component", "billing")),
+		WithHooks(HookFunc(func(_ string, msg Message) Message {
+			if msg.Value("veto") != nil {
+				return nil
+			}
+			return msg
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := atomic.Int32{}
+	counted := func() any { return calls.Add(1) }
+	lgr.With(NewField("component", "billing"), NewField("veto", true), Lazy("n", counted)).Info("vetoed")
+	lgr.With(NewField("component", "auth"), Lazy("n", counted)).Info("filtered")
+	if calls.Load() != 0 {
+		t.Errorf("lazy field of message not written is computed %v times", calls.Load())
+	}
+	lgr.With(NewField("component", "billing"), Lazy("n", counted)).Info("written")
+	if calls.Load() != 1 || buf.String() != "written \n" {
+		t.Errorf("lazy field computed %v times, output %q", calls.Load(), buf.String())
+	}
+}
@@ -1,22 +1,26 @@
 package logman
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"io/fs"
-	"log"
 	"os"
-	"path/filepath"
 	"runtime"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Galdoba/logman/colorizer"
 )
 
-var logMan *logManager
+var logMan atomic.Pointer[Logger]
+
+// ownedDefault is default Logger created by package itself (by init or Setup),
+// so Setup closes it when replacing.
+var ownedDefault atomic.Pointer[Logger]
 var flags int = os.O_CREATE | os.O_WRONLY | os.O_APPEND
 var perm fs.FileMode = 0666
+var dirPerm fs.FileMode = 0755
 
 const (
 	ImportanceNONE  = 100
@@ -45,16 +49,53 @@ const (
 	Stderr = "StdErr"
 )
 
-type logManager struct {
-	appMinimumLoglevel int
-	appName            string
-	logLevels          map[string]*loggingLevel
-	longCallerNames    bool
-	logger             *log.Logger
-	colorizer          Colorizer
-	startTime          time.Time
+// Logger is an independent logging instance with its own levels, writers and formatters.
+// Package-level functions (Info, Warn, Errorf...) delegate to default Logger.
+//
+// Logger is safe for concurrent use. Level table is copy-on-write: every message
+// is processed against an immutable snapshot loaded atomically, while after-setup
+// controls (SetLevelWriterFormatter, ResetWriters, RemovetWriter, SetLevelEnabled, Reload) are serialized,
+// build a modified copy and publish it with a single atomic store.
+// Each formatted line is written with one Write call under a per-destination lock,
+// so lines of concurrent messages never interleave.
+// Formatters and Messages must not be modified once they are handed to Logger.
+//
+// Loggers derived by With share configuration, writers and pipeline of their
+// parent and add bound fields to every message.
+type Logger struct {
+	*loggerCore
+	fields []messageField
+}
+
+// loggerCore is configuration and state shared by Logger and all loggers derived from it.
+type loggerCore struct {
+	appName           string
+	state             atomic.Pointer[snapshot]
+	longCallerNames   bool
+	colorizer         Colorizer
+	startTime         time.Time
+	contextExtractors []func(context.Context) []messageField
+	hooks             []Hook
+	afterHooks        []AfterHook
+	redactor          *Redactor
+	errorStack        bool
+	async             *asyncPipeline
+	override          *importanceOverride
+	retiring          sync.WaitGroup // writers replaced by Reload closed in background
+	replacedBy        atomic.Pointer[loggerCore]
+	mu                sync.Mutex
+}
+
+// levelTable maps level names to levels. Published tables are never modified.
+type levelTable map[string]*loggingLevel
 
-	//activeWriter       string
+// importances maps level names to their importance.
+func (t levelTable) importances() map[string]int {
+	known := make(map[string]int, len(t))
+	for name, lvl := range t {
+		known[name] = lvl.importance
+	}
+	return known
 }
 
 // Colorizer - uses Color Schema to make console output colored depending on fariable type
@@ -63,32 +104,69 @@ type Colorizer interface {
 	ColorizeByKeys(interface{}, ...colorizer.ColorKey) string
 }
 
-// Setup sets logMan options. Place it at start of the program.
-func Setup(opts ...LogmanOptions) error {
-	al := logManager{}
-	al.startTime = time.Now()
-	al.appMinimumLoglevel = ImportanceINFO
-	al.logLevels = make(map[string]*loggingLevel)
-	//al.logLevels = defaultLoggingLevels()
+// New creates Logger with options provided.
+// Levels and formatters are copied, so loggers created with same options do not share state.
+func New(opts ...LogmanOptions) (*Logger, error) {
 	opt := defaultOpts()
 	for _, set := range opts {
 		set(&opt)
 	}
-	for _, lvl := range opt.logLevels {
-		al.logLevels[lvl.name] = lvl
-	}
-	al.appMinimumLoglevel = opt.appMinimumLoglevel
+	al := Logger{loggerCore: &loggerCore{}}
+	al.startTime = time.Now()
 	al.longCallerNames = opt.longCallerNames
 	al.colorizer = opt.colorizer
 	al.appName = opt.appName
+	al.contextExtractors = opt.contextExtractors
+	al.hooks = opt.hooks
+	al.afterHooks = opt.afterHooks
+	al.redactor = opt.redactor
+	al.errorStack = opt.errorStack
+	levels, err := opt.levelTable()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseVerbosity(opt.verbosity, levels.importances())
+	if err != nil {
+		return nil, err
+	}
+	samplers, err := opt.samplers(levels)
+	if err != nil {
+		return nil, err
+	}
+	dedups, err := opt.dedups(levels)
+	if err != nil {
+		return nil, err
+	}
+	writers, _, err := opt.openWriters(nil)
+	if err != nil {
+		return nil, err
+	}
+	al.state.Store(&snapshot{levels: levels, minimum: opt.appMinimumLoglevel, rules: rules, samplers: samplers, dedups: dedups, epoch: newEpoch(writers)})
+	if opt.async != nil {
+		al.async = newAsyncPipeline(&al, *opt.async)
+	}
+	startSamplers(&al, samplers)
+	return &al, nil
+}
+
+// levelTable builds level table of options: levels are copied, console formatters
+// are colorized, global and level writers are attached.
+func (opt *options) levelTable() (levelTable, error) {
+	levels := make(levelTable)
+	for _, lvl := range opt.logLevels {
+		levels[lvl.name] = lvl.clone()
+		if levels[lvl.name].writerFormatterMap == nil {
+			levels[lvl.name].writerFormatterMap = make(map[string]*formatterExpanded)
+		}
+	}
 	//add colors to all console writers.
-	if al.colorizer != nil {
-		for _, lvl := range opt.logLevels {
+	if opt.colorizer != nil {
+		for _, lvl := range levels {
 			for wrtr, formatter := range lvl.writerFormatterMap {
 				switch wrtr {
 				case Stdout, Stderr:
-					if !formatter.customColorizer {
-						formatter.colorizer = al.colorizer
+					if formatter != nil && !formatter.customColorizer {
+						formatter.colorizer = opt.colorizer
 					}
 				default:
 
@@ -99,169 +177,431 @@ func Setup(opts ...LogmanOptions) error {
 	}
 	//add global writers and formatters to all levels
 	for i, writerKey := range opt.globalWriterKeys {
-		for _, lvl := range al.logLevels {
+		for _, lvl := range levels {
 			if _, ok := lvl.writerFormatterMap[writerKey]; ok {
 				continue
 			}
-			if al.logLevels[lvl.name].writerFormatterMap == nil {
-				al.logLevels[lvl.name].writerFormatterMap = make(map[string]*formatterExpanded)
+			lvl.setWriter(writerKey, opt.globalFormatters[i], opt.globalFilters[i])
+		}
+	}
+	//add writers attached to particular levels
+	for _, lw := range opt.levelWriters {
+		lvl, ok := levels[lw.level]
+		if !ok {
+			return nil, fmt.Errorf("logman has no level '%v'", lw.level)
+		}
+		lvl.setWriter(lw.writerKey, lw.formatter, lw.filter)
+	}
+	return levels, nil
+}
+
+// openWriters creates writer registry with writers registered by name. Writers of
+// current registry (locked by caller, may be nil) with the same identity are taken
+// instead of opening them again, their keys are returned.
+func (opt *options) openWriters(current *writerRegistry) (*writerRegistry, map[string]bool, error) {
+	writers := newWriterRegistry(opt.segments)
+	adopted := make(map[string]bool)
+	for _, named := range opt.namedWriters {
+		dest := current.opened(named)
+		if dest != nil {
+			adopted[named.name] = true
+		} else {
+			opened, err := named.open()
+			if err != nil {
+				writers.closeExcept(adopted)
+				return nil, nil, err
 			}
-			al.logLevels[lvl.name].writerFormatterMap[writerKey] = opt.globalFormatters[i]
+			dest = opened
+		}
+		if err := writers.register(named.name, dest); err != nil {
+			writers.closeExcept(adopted)
+			return nil, nil, err
 		}
 	}
-	logMan = &al
+	return writers, adopted, nil
+}
+
+func init() {
+	l := mustDefault()
+	logMan.Store(l)
+	ownedDefault.Store(l)
+}
+
+func mustDefault() *Logger {
+	l, err := New()
+	if err != nil {
+		panic(fmt.Sprintf("logman: default logger: %v", err))
+	}
+	return l
+}
+
+// Setup sets logMan options. Place it at start of the program.
+// Setup replaces default Logger used by package-level functions and closes replaced one
+// if it was created by Setup: its writers are closed and its samplers and deduplicators stopped.
+// Loggers derived from replaced one before (like package-level With, NewSlogHandler(Default()))
+// process messages with new default Logger from then on. Logger set by SetDefault is not closed.
+func Setup(opts ...LogmanOptions) error {
+	l, err := New(opts...)
+	if err != nil {
+		return err
+	}
+	return replaceDefault(l)
+}
+
+// replaceDefault makes l default Logger owned by package. Owned default replaced
+// forwards messages to l and is closed.
+func replaceDefault(l *Logger) error {
+	previous := logMan.Swap(l)
+	if owned := ownedDefault.Swap(l); previous == nil || previous != owned {
+		return nil
+	}
+	previous.replacedBy.Store(l.loggerCore)
+	if err := previous.Close(context.Background()); err != nil {
+		return fmt.Errorf("failed to close previous default logger: %v", err)
+	}
 	return nil
 }
 
+// active returns Logger processing messages of l: Logger derived from default Logger
+// replaced by Setup is resolved to current one with fields of l.
+func (l *Logger) active() *Logger {
+	core := l.loggerCore
+	for next := core.replacedBy.Load(); next != nil; next = core.replacedBy.Load() {
+		core = next
+	}
+	if core == l.loggerCore {
+		return l
+	}
+	return &Logger{loggerCore: core, fields: l.fields}
+}
+
+// Default returns Logger used by package-level functions.
+// It is usable even if Setup was never called.
+func Default() *Logger {
+	return logMan.Load()
+}
+
+// SetDefault replaces Logger used by package-level functions.
+// nil is ignored. Replaced Logger is not closed: caller owns lifecycle of Loggers
+// passed to SetDefault and of Logger returned by Default before the call.
+func SetDefault(l *Logger) {
+	if l == nil {
+		return
+	}
+	logMan.Store(l)
+}
+
+// With returns Logger adding fields to every message it processes.
+// Derived Logger shares configuration and writers with l; fields of l are kept
+// unless overridden by key. Fields set on Message itself take precedence over bound ones.
+func (l *Logger) With(fields ...messageField) *Logger {
+	bound := make([]messageField, len(l.fields), len(l.fields)+len(fields))
+	copy(bound, l.fields)
+	for _, fld := range fields {
+		bound = setBoundField(bound, fld)
+	}
+	return &Logger{loggerCore: l.loggerCore, fields: bound}
+}
+
+// With returns Logger derived from default Logger adding fields to every message.
+func With(fields ...messageField) *Logger {
+	return Default().With(fields...)
+}
+
+func setBoundField(bound []messageField, fld messageField) []messageField {
+	for i := range bound {
+		if bound[i].key == fld.key {
+			bound[i] = fld
+			return bound
+		}
+	}
+	return append(bound, fld)
+}
+
 // ProcessMessage is a general call for processing message.
 // Must be used if custom log levels are used.
+func (l *Logger) ProcessMessage(msg Message, levels ...string) error {
+	return l.process(1, msg, levels...)
+}
+
+// ProcessMessage is a general call for processing message by default Logger.
+// Must be used if custom log levels are used.
 func ProcessMessage(msg Message, levels ...string) error {
-	loggingLevels := []*loggingLevel{}
-	for _, level := range levels {
-		loggingLevels = append(loggingLevels, logMan.logLevels[level])
+	return Default().process(1, msg, levels...)
+}
+
+// levels returns current snapshot of level table.
+func (l *Logger) levels() levelTable {
+	return l.state.Load().levels
+}
+
+// updateLevels applies change to a copy of level table and publishes the copy.
+// Levels are copied with their writer maps, formatters are shared.
+func (l *Logger) updateLevels(change func(levelTable) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	current := l.state.Load()
+	next := make(levelTable, len(current.levels))
+	for name, lvl := range current.levels {
+		next[name] = lvl.detach()
+	}
+	if err := change(next); err != nil {
+		return err
 	}
-	return process(msg, loggingLevels...)
+	snap := *current
+	snap.levels = next
+	l.state.Store(&snap)
+	return nil
 }
 
 // This is main func for processing messages on levels provided.
 // It return processing error of nil if processing successful.
 // If Message is nil function will return with no error.
-func process(msg Message, lvls ...*loggingLevel) error {
-	errorStack := []error{}
-	fatalCalled := false
+// depth is number of stack frames between process and the call site reported as caller.
+// All levels are taken from the same snapshot of level table.
+// In async mode message is prepared on caller's goroutine and written by pipeline workers.
+func (l *Logger) process(depth int, msg Message, levels ...string) error {
+	return l.processCtx(nil, depth+1, msg, levels...)
+}
+
+// processCtx processes message with request-scoped fields and minimum importance
+// override of ctx. ctx may be nil.
+func (l *Logger) processCtx(ctx context.Context, depth int, msg Message, levels ...string) error {
 	if msg == nil {
 		return nil
 	}
-	for _, lvl := range lvls {
+	l = l.active()
+	dlv := l.prepare(ctx, depth+1, msg, levels...)
+	if l.async != nil && !dlv.fatal {
+		errs := dlv.errs
+		if len(dlv.levels) == 0 {
+			dlv.recycle()
+			return joinErrors("processing message failed", errs...)
+		}
+		if err := l.async.enqueue(dlv); err != nil {
+			dlv.release()
+			dlv.recycle()
+			errs = append(errs, err)
+		}
+		return joinErrors("processing message failed", errs...)
+	}
+	if l.async != nil {
+		l.async.flush(context.Background())
+	}
+	errorStack := append(dlv.errs, l.deliver(dlv)...)
+	fatal := dlv.fatal
+	if fatal && len(errorStack) == 0 {
+		dlv.snap.epoch.writers.Sync()
+	}
+	dlv.release()
+	dlv.recycle()
+	if err := joinErrors("processing message failed", errorStack...); err != nil {
+		return err
+	}
+	if fatal {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// delivery is a message prepared for writing on levels.
+type delivery struct {
+	snap       *snapshot
+	msg        Message
+	levels     []*loggingLevel
+	importance int
+	fatal      bool
+	caller     bool
+	file       string
+	line       int
+	funcName   string
+	errs       []error
+	results    []WriteResult
+}
+
+// prepare resolves levels, filters them by importance and captures caller information.
+// Fields set on message take precedence over bound fields of Logger, which take
+// precedence over fields of ctx.
+// It must run on caller's goroutine.
+func (l *Logger) prepare(ctx context.Context, depth int, msg Message, levels ...string) *delivery {
+	bindLogger(msg, l)
+	bindContext(msg, ctx)
+	for _, fld := range l.fields {
+		if msg.Value(fld.key) == nil {
+			msg.SetField(fld.key, fresh(fld.value))
+		}
+	}
+	if ctx != nil {
+		for _, fld := range l.contextFields(ctx) {
+			if msg.Value(fld.key) == nil {
+				msg.SetField(fld.key, fresh(fld.value))
+			}
+		}
+	}
+	snap := l.acquire()
+	minimum := snap.minimum
+	if snap.rules != nil {
+		minimum = snap.rules.minimum(2+depth, msg, minimum)
+	}
+	if importance, ok := ImportanceFromContext(ctx); ok {
+		minimum = importance
+	}
+	dlv := deliveryPool.Get().(*delivery)
+	*dlv = delivery{snap: snap, msg: msg, levels: dlv.levels[:0], results: dlv.results[:0]}
+	for _, level := range levels {
+		lvl := snap.levels[level]
 		if lvl == nil {
-			errorStack = append(errorStack, fmt.Errorf("logginglevel provided was not set"))
+			dlv.errs = append(dlv.errs, fmt.Errorf("logginglevel provided was not set"))
 			continue
 		}
-		if lvl.importance < logMan.appMinimumLoglevel {
+		if lvl.disabled || lvl.importance < minimum {
 			continue
 		}
-		if !isPresent(lvl) {
-			errorStack = append(errorStack, fmt.Errorf("level %v was not set properly", lvl.name))
+		if smp := snap.samplers[lvl.name]; smp != nil && !smp.allow(msg) {
 			continue
 		}
+		dlv.levels = append(dlv.levels, lvl)
+		if lvl.importance > dlv.importance {
+			dlv.importance = lvl.importance
+		}
+		if lvl.osExit {
+			dlv.fatal = true
+		}
+		if lvl.callerInfo && !dlv.caller {
+			dlv.file, dlv.line, dlv.funcName = callerFunctionInfo(2 + depth)
+			dlv.caller = true
+		}
+	}
+	if len(dlv.levels) == 0 {
+		dlv.release()
+	}
+	return dlv
+}
 
-		for _, present := range logMan.logLevels {
-			if lvl.name != present.name {
-				continue
-			}
-			msg.SetField(keyLevel, lvl.tag)
+var deliveryPool = sync.Pool{
+	New: func() any {
+		return &delivery{}
+	},
+}
 
-			if lvl.callerInfo {
-				file, line, fn := callerFunctionInfo(3)
-				if msg.Value(keyFile) == nil {
-					msg.SetField(keyFile, file)
-				}
-				if msg.Value(keyLine) == nil {
-					msg.SetField(keyLine, line)
-				}
-				if msg.Value(keyFunc) == nil {
-					msg.SetField(keyFunc, fn)
-				}
-			}
+// recycle returns delivery and message created by Logger to pools. Delivery must be released.
+func (dlv *delivery) recycle() {
+	recycle(dlv.msg)
+	clear(dlv.levels)
+	clear(dlv.results)
+	*dlv = delivery{levels: dlv.levels[:0], results: dlv.results[:0]}
+	deliveryPool.Put(dlv)
+}
 
-			if err := lvl.write(msg); err != nil {
-				errorStack = append(errorStack, fmt.Errorf("writting message failed: %v", err))
-			}
+// release marks delivery as finished with writers of its snapshot. It is safe to call more than once.
+func (dlv *delivery) release() {
+	if dlv.snap != nil {
+		dlv.snap.epoch.release()
+		dlv.snap = nil
+	}
+}
+
+// deliver writes prepared message to writers of its levels.
+func (l *Logger) deliver(dlv *delivery) []error {
+	errorStack := []error{}
+	msg := dlv.msg
+	for _, lvl := range dlv.levels {
+		msg.SetField(keyLevel, lvl.tagField())
 
-			if lvl.osExit {
-				fatalCalled = true
+		if lvl.callerInfo {
+			if msg.Value(keyFile) == nil {
+				msg.SetField(keyFile, dlv.file)
+			}
+			if msg.Value(keyLine) == nil {
+				msg.SetField(keyLine, dlv.line)
+			}
+			if msg.Value(keyFunc) == nil {
+				msg.SetField(keyFunc, dlv.funcName)
 			}
 		}
+
+		written := l.runHooks(lvl.name, msg)
+		if written == nil {
+			continue
+		}
+		if dd := dlv.snap.dedups[lvl.name]; dd != nil && !dd.pass(l, lvl, dlv.snap.epoch.writers, written) {
+			continue
+		}
+		dlv.results = l.emit(lvl, dlv.snap.epoch.writers, written, dlv.results[:0])
+		if err := resultsError(dlv.results); err != nil {
+			errorStack = append(errorStack, fmt.Errorf("writting message failed: %v", err))
+		}
 	}
-	if err := joinErrors("processing message failed", errorStack...); err != nil {
-		return err
-	}
-	if fatalCalled {
-		os.Exit(1)
-	}
-	return nil
+	return errorStack
 }
 
-func isPresent(lvl *loggingLevel) bool {
-	for _, present := range logMan.logLevels {
-		if lvl.name == present.name && lvl.tag == present.tag {
-			return true
-		}
+// emit writes message passed hooks to writers of level and runs after hooks.
+func (l *Logger) emit(lvl *loggingLevel, writers *writerRegistry, msg Message, results []WriteResult) []WriteResult {
+	results = lvl.writeResults(l, writers, msg, results)
+	l.runAfterHooks(lvl.name, msg, results)
+	return results
+}
+
+// deliverSummary passes summary made by Logger (like repeats of deduplicated message)
+// through hooks and redactor to writers of level. Summary is not deduplicated itself.
+func (l *Logger) deliverSummary(lvl *loggingLevel, writers *writerRegistry, summary Message) error {
+	if summary == nil {
+		return nil
 	}
-	return false
+	bindLogger(summary, l)
+	written := l.runHooks(lvl.name, summary)
+	if written == nil {
+		return nil
+	}
+	return resultsError(l.emit(lvl, writers, written, nil))
 }
 
-func (lvl *loggingLevel) write(message Message) error {
-	errorStack := []error{}
-	var writer io.Writer
+// writeResults writes message to every writer of level passing its filter and appends results.
+// Lazy fields are resolved and message is redacted when first writer accepts it.
+func (lvl *loggingLevel) writeResults(l *Logger, writers *writerRegistry, message Message, results []WriteResult) []WriteResult {
+	resolved := false
 	for writerKey, formatter := range lvl.writerFormatterMap {
-		switch writerKey {
-		case Stderr:
-			writer = os.Stderr
-		case Stdout:
-			writer = os.Stdout
-		default:
-			switch writerInfo(writerKey) {
-			case "file":
-				wr, err := os.OpenFile(writerKey, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
-				switch err {
-				case nil:
-					writer = wr
-				default:
-					errorStack = append(errorStack, fmt.Errorf("failed to open writer '%v'", writerKey))
-					continue
-				}
-			case "dir":
-
-				sep := string(filepath.Separator)
-				dirPath := strings.TrimSuffix(writerKey, sep) + sep
-				msgTime, err := time.Parse(time.RFC3339Nano, fmt.Sprintf("%v", message.Value("time")))
-				if err != nil {
-					msgTime = time.Now()
-				}
-				msgFile := fmt.Sprintf("%v%v_%v_%v.lmm", dirPath, msgTime.UnixNano(), logMan.appName, lvl.name)
-				wr, err := os.OpenFile(msgFile, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
-				switch err {
-				case nil:
-					writer = wr
-				default:
-					errorStack = append(errorStack, fmt.Errorf("failed to open writer '%v'", writerKey))
-					continue
-				}
-			}
+		if !lvl.writerFilters[writerKey].allow(lvl, message) {
+			continue
 		}
-		text := formatter.Format(message, true)
-		text = strings.TrimSuffix(text, "\n") + "\n"
-		bt := []byte(text)
-		_, err := writer.Write(bt)
-		if err != nil {
-			errorStack = append(errorStack, err)
+		if !resolved {
+			resolveLazy(message)
+			if l.redactor != nil {
+				message = l.redactor.Before(lvl.name, message)
+			}
+			resolved = true
 		}
+		results = append(results, WriteResult{Writer: writerKey, Err: writeTo(l, lvl, writers, writerKey, formatter, message)})
 	}
-	if err := joinErrors("writing message failed", errorStack...); err != nil {
-		return err
-	}
-	return nil
+	return results
 }
 
-func writerInfo(path string) string {
-	f, err := os.Stat(path)
+func writeTo(l *Logger, lvl *loggingLevel, writers *writerRegistry, writerKey string, formatter *formatterExpanded, message Message) error {
+	dest, err := writers.get(writerKey)
 	if err != nil {
-		return "bad"
+		return err
+	}
+	if formatter == nil {
+		return dest.write(l.appName, lvl.name, message, nil)
 	}
-	if f.IsDir() {
-		return "dir"
+	buf := getBuffer()
+	defer putBuffer(buf)
+	text := formatter.appendFormat(buf.b[:0], message, true)
+	if len(text) == 0 || text[len(text)-1] != '\n' {
+		text = append(text, '\n')
 	}
-	if f.Mode().IsRegular() {
-		fl, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0666)
-		if err != nil {
-			fl.Close()
-			return "bad"
+	buf.b = text
+	return dest.write(l.appName, lvl.name, message, text)
+}
+
+func resultsError(results []WriteResult) error {
+	errorStack := []error{}
+	for _, result := range results {
+		if result.Err != nil {
+			errorStack = append(errorStack, result.Err)
 		}
-		fl.Close()
-		return "file"
 	}
-	return "bad"
+	return joinErrors("writing message failed", errorStack...)
 }
 
 func joinErrors(message string, errs ...error) error {
@@ -1,6 +1,12 @@
 package logman
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Galdoba/logman/colorizer"
@@ -22,3 +28,104 @@ func TestLogMan(t *testing.T) {
 	// SetOutput(Stderr, ALL)
 	Info("test error: %v", "some error")
 }
+
+func TestLoggerInstances(t *testing.T) {
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.log")
+	pathB := filepath.Join(dir, "b.log")
+	for _, path := range []string{pathA, pathB} {
+		if err := os.WriteFile(path, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgrA, err := New(WithLogLevels(NewLoggingLevel(INFO, WithWriter(pathA, formatter))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgrB, err := New(WithLogLevels(NewLoggingLevel(INFO, WithWriter(pathB, formatter))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lgrA.Info("to %v", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lgrB.Info("to %v", "b"); err != nil {
+		t.Fatal(err)
+	}
+	for path, expected := range map[string]string{pathA: "to a \n", pathB: "to b \n"} {
+		bt, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bt) != expected {
+			t.Errorf("%v: expected %q, have %q", filepath.Base(path), expected, string(bt))
+		}
+	}
+}
+
+func TestChildLoggers(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewFormatter(WithRequestedFields([]string{keyMessage, "component", "request", "tenant"}))
+	lgr, err := New(WithLogLevels(NewLoggingLevel(INFO)), WithWriterNamed("buffer", buf, formatter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := lgr.With(NewField("component", "billing"), NewField("tenant", "acme"))
+	request := service.With(NewField("request", 7), NewField("tenant", "globex"))
+	request.Info("charged")
+	service.Info("idle")
+	request.ProcessMessage(NewMessage("explicit").WithFields(NewField("request", 8)), INFO)
+	expected := "charged component=billing request=7 tenant=globex \n" +
+		"idle component=billing request=<nil> tenant=acme \n" +
+		"explicit component=billing request=8 tenant=globex \n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\nhave:\n%v", expected, buf.String())
+	}
+}
+
+func TestChildLoggersJSON(t *testing.T) {
+	buf := &lockedBuffer{}
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(WARN, LevelImportance(ImportanceWARN))),
+		WithWriterNamed("json", buf, NewFormatter(WithCustomFunc("json", stdJSON), WithRequestedFields([]string{"json"}))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := lgr.With(String("service", "api"))
+	if err := service.ProcessMessage(NewMessage("started %v", 1).WithFields(Int("port", 8080)), INFO); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Warn("slow %v", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, have %q", buf.String())
+	}
+	for i, expected := range []map[string]interface{}{
+		{"service": "api", "port": float64(8080)},
+		{"service": "api"},
+	} {
+		decoded := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(lines[i]), &decoded); err != nil {
+			t.Fatalf("invalid json %v: %v", lines[i], err)
+		}
+		keys, _ := decoded["logman keys"].(map[string]interface{})
+		if len(keys) != len(expected) {
+			t.Errorf("line %v: expected bound fields %v, have %v", i, expected, lines[i])
+		}
+		for key, value := range expected {
+			if keys[key] != value {
+				t.Errorf("line %v: expected %v=%v, have %v", i, key, value, lines[i])
+			}
+		}
+		if _, ok := decoded["input arguments"]; ok {
+			t.Errorf("line %v: input arguments are written: %v", i, lines[i])
+		}
+	}
+}
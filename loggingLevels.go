@@ -19,10 +19,13 @@ type loggingLevel struct {
 	importance         int
 	callerInfo         bool
 	osExit             bool
+	disabled           bool
 	colorSchemes       map[string]uint8
 	formatFunc         func(Message) (string, error)
 	FMTE               *formatterExpanded
 	writerFormatterMap map[string]*formatterExpanded
+	writerFilters      map[string]*writerFilter
+	tagValue           interface{} // tag boxed once by clone, set as field "level"
 }
 
 func NewLoggingLevel(name string, opts ...LevelOpts) *loggingLevel {
@@ -37,12 +40,63 @@ func NewLoggingLevel(name string, opts ...LevelOpts) *loggingLevel {
 	lo.osExit = options.osExit
 	lo.importance = options.importance
 	lo.writerFormatterMap = options.writerFormatterMap
+	lo.writerFilters = options.writerFilters
 	if options.tag != "" {
 		lo.tag = options.tag
 	}
 	return &lo
 }
 
+func (lvl *loggingLevel) clone() *loggingLevel {
+	cp := *lvl
+	cp.tagValue = cp.tag
+	cp.writerFormatterMap = make(map[string]*formatterExpanded)
+	for key, formatter := range lvl.writerFormatterMap {
+		cp.writerFormatterMap[key] = formatter.clone()
+	}
+	cp.writerFilters = copyFilters(lvl.writerFilters)
+	return &cp
+}
+
+// detach returns copy of level with own writer map. Formatters are shared.
+func (lvl *loggingLevel) detach() *loggingLevel {
+	cp := *lvl
+	cp.writerFormatterMap = make(map[string]*formatterExpanded, len(lvl.writerFormatterMap))
+	for key, formatter := range lvl.writerFormatterMap {
+		cp.writerFormatterMap[key] = formatter
+	}
+	cp.writerFilters = copyFilters(lvl.writerFilters)
+	return &cp
+}
+
+// tagField returns tag as field value without allocation.
+func (lvl *loggingLevel) tagField() interface{} {
+	if lvl.tagValue != nil {
+		return lvl.tagValue
+	}
+	return lvl.tag
+}
+
+// copyFilters copies filter map. Filters are shared.
+func copyFilters(filters map[string]*writerFilter) map[string]*writerFilter {
+	cp := make(map[string]*writerFilter, len(filters))
+	for key, filter := range filters {
+		cp[key] = filter
+	}
+	return cp
+}
+
+// setWriter attaches writer with formatter and filter to level.
+func (lvl *loggingLevel) setWriter(writerKey string, formatter *formatterExpanded, filter *writerFilter) {
+	lvl.writerFormatterMap[writerKey] = formatter
+	switch filter {
+	case nil:
+		delete(lvl.writerFilters, writerKey)
+	default:
+		lvl.writerFilters[writerKey] = filter
+	}
+}
+
 func LevelTag(tag string) LevelOpts {
 	return func(lvl *lvlOpts) {
 		lvl.tag = tag
@@ -73,6 +127,7 @@ type lvlOpts struct {
 	callerInfo         bool
 	osExit             bool
 	writerFormatterMap map[string]*formatterExpanded
+	writerFilters      map[string]*writerFilter
 }
 
 func defaultLevel() lvlOpts {
@@ -81,13 +136,20 @@ func defaultLevel() lvlOpts {
 		importance:         ImportanceINFO,
 		callerInfo:         false,
 		writerFormatterMap: make(map[string]*formatterExpanded),
+		writerFilters:      make(map[string]*writerFilter),
 	}
 }
 
 type LevelOpts func(*lvlOpts)
 
-func WithWriter(writerKey string, expandedFormatter *formatterExpanded) LevelOpts {
+// WithWriter attaches writer to level. Writer options set importance floor and
+// predicates deciding which messages are written to it.
+func WithWriter(writerKey string, expandedFormatter *formatterExpanded, opts ...WriterOption) LevelOpts {
+	filter := newWriterFilter(opts...)
 	return func(lo *lvlOpts) {
 		lo.writerFormatterMap[writerKey] = expandedFormatter
+		if filter != nil {
+			lo.writerFilters[writerKey] = filter
+		}
 	}
 }
@@ -1,44 +1,76 @@
 package logman
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// message keeps fields in order they were set. Fields "time" and "message" are
+// always present, text of message is rendered from format and arguments when first requested.
 type message struct {
-	fields      map[string]interface{}
-	inputArgs   map[int]interface{}
+	format      string
+	formatArgs  []interface{}
+	fields      []messageField
 	timeCreated time.Time
+	rendered    string
+	logger      *Logger
+	ctx         context.Context
+	ownTime     bool // field "time" is timeCreated
+	ownText     bool // field "message" is text rendered from format and arguments
+	render      bool // text is not rendered yet
+	lazy        bool // has lazy fields
+	pooled      bool // returned to pool after processing
+	summary     bool // summary of sampler, it is never sampled
 }
 
+// NewMessage creates message. Text of message is rendered when it is first requested,
+// so messages filtered out by Logger are never formatted.
 func NewMessage(format string, args ...interface{}) *message {
 	m := message{}
-	m.fields = make(map[string]interface{})
-	m.inputArgs = make(map[int]interface{})
-	for _, arg := range args {
-		m.inputArgs[len(m.inputArgs)] = arg
-	}
-	m.inputArgs[-1] = format
-	timeCreated := time.Now()
-	m.fields[keyMessage] = fmt.Sprintf(format, args...)
-	m.fields[keyTime] = timeCreated.Format(time.RFC3339Nano)
+	m.init(format, args)
 	return &m
 }
 
-func combineColored(format string, args ...string) string {
-	fmtParts := strings.Split(format, `%v`)
-	combined := ""
-	for i, part := range fmtParts {
-		combined += part
-		if i < len(args) {
-			combined += args[i]
-		}
+var messagePool = sync.Pool{
+	New: func() any {
+		return &message{pooled: true}
+	},
+}
+
+// newPooledMessage creates message which is returned to pool after Logger processed it.
+// Arguments are copied.
+func newPooledMessage(format string, args []interface{}) *message {
+	m := messagePool.Get().(*message)
+	m.init(format, args)
+	return m
+}
+
+func (m *message) init(format string, args []interface{}) {
+	m.format = format
+	m.formatArgs = append(m.formatArgs[:0], args...)
+	m.timeCreated = time.Now()
+	m.fields = append(m.fields[:0], messageField{keyTime, nil}, messageField{keyMessage, nil})
+	m.rendered = ""
+	m.ownTime, m.ownText, m.render = true, true, true
+}
+
+// recycle returns pooled message to pool. Message must not be used after it.
+func recycle(msg Message) {
+	m, ok := msg.(*message)
+	if !ok || !m.pooled {
+		return
 	}
-	return combined
+	clear(m.formatArgs)
+	clear(m.fields)
+	m.formatArgs, m.fields = m.formatArgs[:0], m.fields[:0]
+	m.logger, m.ctx, m.lazy, m.summary, m.rendered = nil, nil, false, false, ""
+	messagePool.Put(m)
 }
 
 // Message is an interface to a struct to set/get/list data fields.
@@ -50,18 +82,34 @@ type Message interface {
 }
 
 // Value return variable that serves as state if field.
+// Field "time" is time.Time of message creation unless it was set.
 func (m *message) Value(key string) interface{} {
-	if val, ok := m.fields[key]; ok {
-		return val
+	switch {
+	case key == keyTime && m.ownTime:
+		return m.timeCreated
+	case key == keyMessage && m.ownText:
+		return m.text()
+	}
+	if i := m.index(key); i >= 0 {
+		return m.fields[i].value
 	}
 	return nil
 }
 
+func (m *message) index(key string) int {
+	for i := range m.fields {
+		if m.fields[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
 // Fields return sorted list of keys for contained fields.
 func (m *message) Fields() []string {
-	keys := []string{}
-	for k := range m.fields {
-		keys = append(keys, k)
+	keys := make([]string, 0, len(m.fields))
+	for _, fld := range m.fields {
+		keys = append(keys, fld.key)
 	}
 	sort.Strings(keys)
 	return keys
@@ -69,54 +117,143 @@ func (m *message) Fields() []string {
 
 // SetField - sets/override fields value.
 func (m *message) SetField(key string, value interface{}) {
-	m.fields[key] = value
+	if _, ok := value.(*LazyValue); ok {
+		m.lazy = true
+	}
+	switch key {
+	case keyTime:
+		m.ownTime = false
+	case keyMessage:
+		m.ownText = false
+	}
+	if i := m.index(key); i >= 0 {
+		m.fields[i].value = value
+		return
+	}
+	m.fields = append(m.fields, messageField{key, value})
+}
+
+func (m *message) deleteField(key string) {
+	if i := m.index(key); i >= 0 {
+		m.fields = append(m.fields[:i], m.fields[i+1:]...)
+	}
+}
+
+// text returns text of message rendering it on first call.
+func (m *message) text() string {
+	if !m.ownText {
+		if text, ok := m.Value(keyMessage).(string); ok {
+			return text
+		}
+		return fmt.Sprint(m.Value(keyMessage))
+	}
+	if m.render {
+		m.rendered = fmt.Sprintf(m.format, m.formatArgs...)
+		m.render = false
+	}
+	return m.rendered
+}
+
+// setText replaces text of message and its format and arguments.
+func (m *message) setText(text string) {
+	m.format = escapeFormat(text)
+	clear(m.formatArgs)
+	m.formatArgs = m.formatArgs[:0]
+	m.rendered, m.render = text, false
+	if !m.ownText {
+		m.SetField(keyMessage, text)
+	}
+}
+
+// createdAt returns value of field "time" of message.
+func createdAt(msg Message) (time.Time, bool) {
+	if m, ok := msg.(*message); ok && m.ownTime {
+		return m.timeCreated, true
+	}
+	tm, ok := msg.Value(keyTime).(time.Time)
+	return tm, ok
 }
 
 // InputArgs - returns map of arguments if NewMessage() function.
 // key -1 => is format string
 // other keys is position number of argument
+// Map is created on every call.
 func (m *message) InputArgs() map[int]interface{} {
-	return m.inputArgs
+	inputs := make(map[int]interface{}, len(m.formatArgs)+1)
+	inputs[-1] = m.format
+	for i, arg := range m.formatArgs {
+		inputs[i] = arg
+	}
+	return inputs
 }
 
 // WithFields sets multiple fields to a message.
 func (m *message) WithFields(flds ...messageField) *message {
 	for _, fld := range flds {
-		m.fields[fld.key] = fld.value
+		m.SetField(fld.key, fld.value)
 	}
 	return m
 }
 
 // WithArgs overrided special type of fields with keys "arg №", where № is number in order of appearence.
+// Arguments keep their types.
 func (m *message) WithArgs(args ...interface{}) *message {
-	fldLen := len(m.fields)
-	for i := 0; i < fldLen; i++ {
-		key := fmt.Sprintf("arg %v", i)
-		if _, ok := m.fields[fmt.Sprintf("arg %v", i)]; ok {
-			delete(m.fields, key)
+	kept := m.fields[:0]
+	for _, fld := range m.fields {
+		if !isArgField(fld) {
+			kept = append(kept, fld)
 		}
 	}
+	m.fields = kept
 	for i, arg := range args {
-		newKey := fmt.Sprintf("arg %v", i)
-		m.fields[newKey] = fmt.Sprintf("%v %T", arg, arg)
+		m.SetField(fmt.Sprintf("arg %v", i), arg)
 	}
 	return m
 }
 
 func (m *message) args() []messageField {
-	fldLen := len(m.fields)
 	argFields := []messageField{}
-	for i := 0; i < fldLen; i++ {
+	for i := 0; i < len(m.fields); i++ {
 		key := fmt.Sprintf("arg %v", i)
-		if val, ok := m.fields[fmt.Sprintf("arg %v", i)]; ok {
-			argFields = append(argFields, messageField{key, val})
+		if j := m.index(key); j >= 0 {
+			argFields = append(argFields, m.fields[j])
 		}
 	}
 	return argFields
 }
 
+// bindLogger remembers Logger processing message, so formatters can reach its settings.
+func bindLogger(msg Message, l *Logger) {
+	if m, ok := msg.(*message); ok {
+		m.logger = l
+	}
+}
+
+// bindContext remembers context message is processed with, so writers can pass it on.
+func bindContext(msg Message, ctx context.Context) {
+	if m, ok := msg.(*message); ok {
+		m.ctx = ctx
+	}
+}
+
+// contextOf returns context message is processed with or context.Background if it is unknown.
+func contextOf(msg Message) context.Context {
+	if m, ok := msg.(*message); ok && m.ctx != nil {
+		return m.ctx
+	}
+	return context.Background()
+}
+
+// loggerOf returns Logger processing message or default Logger if it is unknown.
+func loggerOf(msg Message) *Logger {
+	if m, ok := msg.(*message); ok && m.logger != nil {
+		return m.logger
+	}
+	return Default()
+}
+
 func (m *message) clearLevel() {
-	delete(m.fields, keyLevel)
+	m.deleteField(keyLevel)
 }
 
 type messageField struct {
@@ -195,6 +332,15 @@ func (m *message) unmarshalJSON(data []byte) error {
 		return err
 	}
 	// Set the fields to the new struct,
-	m.fields = realMessage.Fields
+	keys := make([]string, 0, len(realMessage.Fields))
+	for key := range realMessage.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	m.fields = m.fields[:0]
+	for _, key := range keys {
+		m.fields = append(m.fields, messageField{key, realMessage.Fields[key]})
+	}
+	m.ownTime, m.ownText, m.render = false, false, false
 	return nil
 }
@@ -0,0 +1,5 @@
+//go:build !race
+
+package logman
+
+const raceEnabled = false
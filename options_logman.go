@@ -1,8 +1,12 @@
 package logman
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"io"
+)
 
-// LogmanOptions - settings for logMan object.
+// LogmanOptions - settings for Logger object.
 type LogmanOptions func(*options)
 
 type options struct {
@@ -13,12 +17,42 @@ type options struct {
 	colorizer          Colorizer
 	globalWriterKeys   []string
 	globalFormatters   []*formatterExpanded
+	globalFilters      []*writerFilter
+	namedWriters       []namedWriter
+	levelWriters       []levelWriter
+	segments           segmentSettings
+	async              *asyncSettings
+	contextExtractors  []func(context.Context) []messageField
+	verbosity          string
+	sampling           map[string]samplingSettings
+	dedup              *dedupSettings
+	hooks              []Hook
+	afterHooks         []AfterHook
+	redactor           *Redactor
+	errorStack         bool
+}
+
+// namedWriter is writer registered by name. open is called once by New.
+// Reload keeps destination opened before if identity is not empty and the same.
+type namedWriter struct {
+	name     string
+	identity string
+	open     func() (destination, error)
+}
+
+// levelWriter is writer attached to a single level by New.
+type levelWriter struct {
+	level     string
+	writerKey string
+	formatter *formatterExpanded
+	filter    *writerFilter
 }
 
 func defaultOpts() options {
 	return options{
 		appMinimumLoglevel: ImportanceALL,
 		logLevels:          defaultLoggingLevels(),
+		segments:           defaultSegmentSettings(),
 	}
 
 }
@@ -58,11 +92,14 @@ func WithGlobalColorizer(colorizer Colorizer) LogmanOptions {
 }
 
 // WithGlobalWriterFormatter - Add writer to all level.
-// Useful to setup logfile.
-func WithGlobalWriterFormatter(writer string, formatter *formatterExpanded) LogmanOptions {
+// Useful to setup logfile. Writer options set importance floor and predicates
+// deciding which messages are written to it.
+func WithGlobalWriterFormatter(writer string, formatter *formatterExpanded, opts ...WriterOption) LogmanOptions {
+	filter := newWriterFilter(opts...)
 	return func(o *options) {
 		o.globalWriterKeys = append(o.globalWriterKeys, writer)
 		o.globalFormatters = append(o.globalFormatters, formatter)
+		o.globalFilters = append(o.globalFilters, filter)
 	}
 }
 
@@ -73,6 +110,30 @@ func WithJSON(directory string) LogmanOptions {
 	return func(o *options) {
 		o.globalWriterKeys = append(o.globalWriterKeys, directory)
 		o.globalFormatters = append(o.globalFormatters, formatter)
+		o.globalFilters = append(o.globalFilters, nil)
+	}
+}
+
+// WithWriterNamed - registers io.Writer under name and adds it to all levels with formatter.
+// If formatter is nil writer is only registered and can be attached to levels with WithWriter(name, formatter).
+// Useful to log into buffers, pipes, network connections or custom sinks.
+func WithWriterNamed(name string, w io.Writer, formatter *formatterExpanded) LogmanOptions {
+	return func(o *options) {
+		o.namedWriters = append(o.namedWriters, namedWriter{
+			name: name,
+			open: func() (destination, error) {
+				if w == nil {
+					return nil, fmt.Errorf("writer '%v' is nil", name)
+				}
+				return &streamDestination{writer: w}, nil
+			},
+		})
+		if formatter == nil {
+			return
+		}
+		o.globalWriterKeys = append(o.globalWriterKeys, name)
+		o.globalFormatters = append(o.globalFormatters, formatter)
+		o.globalFilters = append(o.globalFilters, nil)
 	}
 }
 
@@ -84,31 +145,59 @@ func WithAppName(name string) LogmanOptions {
 
 //AFTER SETUP CONTROL
 
-func SetLevelWriterFormatter(level, writer string, formatter *formatterExpanded) error {
-	if _, ok := logMan.logLevels[level]; !ok {
-		return fmt.Errorf("logman has no level '%v'", level)
-	}
-	logMan.logLevels[level].writerFormatterMap[writer] = formatter
-	return nil
+// SetLevelWriterFormatter sets formatter for writer of level on default Logger.
+func SetLevelWriterFormatter(level, writer string, formatter *formatterExpanded, opts ...WriterOption) error {
+	return Default().SetLevelWriterFormatter(level, writer, formatter, opts...)
 }
 
-func ResetWriters(levels ...string) error {
-	for _, level := range levels {
-		if _, ok := logMan.logLevels[level]; !ok {
+// SetLevelWriterFormatter sets formatter for writer of level.
+// Writer options replace importance floor and predicates of writer.
+func (l *Logger) SetLevelWriterFormatter(level, writer string, formatter *formatterExpanded, opts ...WriterOption) error {
+	filter := newWriterFilter(opts...)
+	return l.updateLevels(func(levels levelTable) error {
+		if _, ok := levels[level]; !ok {
 			return fmt.Errorf("logman has no level '%v'", level)
 		}
-		logMan.logLevels[level].writerFormatterMap = make(map[string]*formatterExpanded)
-	}
-	return nil
+		levels[level].setWriter(writer, formatter, filter)
+		return nil
+	})
 }
 
+// ResetWriters removes all writers from levels of default Logger.
+func ResetWriters(levels ...string) error {
+	return Default().ResetWriters(levels...)
+}
+
+// ResetWriters removes all writers from levels.
+func (l *Logger) ResetWriters(levels ...string) error {
+	return l.updateLevels(func(table levelTable) error {
+		for _, level := range levels {
+			if _, ok := table[level]; !ok {
+				return fmt.Errorf("logman has no level '%v'", level)
+			}
+			table[level].writerFormatterMap = make(map[string]*formatterExpanded)
+			table[level].writerFilters = make(map[string]*writerFilter)
+		}
+		return nil
+	})
+}
+
+// RemovetWriter removes writer from level of default Logger.
 func RemovetWriter(level, writer string) error {
-	if _, ok := logMan.logLevels[level]; !ok {
-		return fmt.Errorf("logman has no level '%v'", level)
-	}
-	if _, ok := logMan.logLevels[level].writerFormatterMap[writer]; !ok {
-		return fmt.Errorf("logman level '%v' has no writer '%v'", level, writer)
-	}
-	delete(logMan.logLevels[level].writerFormatterMap, writer)
-	return nil
+	return Default().RemovetWriter(level, writer)
+}
+
+// RemovetWriter removes writer from level.
+func (l *Logger) RemovetWriter(level, writer string) error {
+	return l.updateLevels(func(levels levelTable) error {
+		if _, ok := levels[level]; !ok {
+			return fmt.Errorf("logman has no level '%v'", level)
+		}
+		if _, ok := levels[level].writerFormatterMap[writer]; !ok {
+			return fmt.Errorf("logman level '%v' has no writer '%v'", level, writer)
+		}
+		delete(levels[level].writerFormatterMap, writer)
+		delete(levels[level].writerFilters, writer)
+		return nil
+	})
 }
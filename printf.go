@@ -0,0 +1,250 @@
+package logman
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/Galdoba/logman/colorizer"
+	"github.com/gookit/color"
+)
+
+// strings written by fmt for malformed formats.
+const (
+	fmtBadWidth = "%!(BADWIDTH)"
+	fmtBadPrec  = "%!(BADPREC)"
+	fmtNoVerb   = "%!(NOVERB)"
+	fmtBadIndex = "(BADINDEX)"
+	fmtMissing  = "(MISSING)"
+	fmtExtra    = "%!(EXTRA "
+)
+
+// colorSprintf formats like fmt.Sprintf, but every formatted argument is colorized by its type.
+// Format string is parsed the same way fmt does it, so text without escape codes
+// is identical to fmt.Sprintf(format, args...).
+func colorSprintf(colors Colorizer, format string, args []interface{}) string {
+	buf := make([]byte, 0, len(format)+16*len(args))
+	end := len(format)
+	argNum := 0
+	afterIndex := false
+	reordered := false
+	for i := 0; i < end; {
+		goodArgNum := true
+		lasti := i
+		for i < end && format[i] != '%' {
+			i++
+		}
+		buf = append(buf, format[lasti:i]...)
+		if i >= end {
+			break
+		}
+		i++
+
+		// directive is rebuilt without argument indexes and with width and precision resolved.
+		directive := []byte{'%'}
+		for ; i < end; i++ {
+			c := format[i]
+			if c != '#' && c != '0' && c != '+' && c != '-' && c != ' ' {
+				break
+			}
+			directive = append(directive, c)
+		}
+
+		argNum, i, afterIndex, reordered = argNumber(format, i, argNum, len(args), &goodArgNum, reordered)
+
+		if i < end && format[i] == '*' {
+			i++
+			var wid int
+			var ok bool
+			wid, ok, argNum = intFromArg(args, argNum)
+			if !ok {
+				buf = append(buf, fmtBadWidth...)
+			}
+			if wid < 0 {
+				wid = -wid
+				directive = append(withoutZero(directive), '-')
+			}
+			if ok {
+				directive = strconv.AppendInt(directive, int64(wid), 10)
+			}
+			afterIndex = false
+		} else {
+			wid, ok, next := parseNum(format, i, end)
+			i = next
+			if ok {
+				directive = strconv.AppendInt(directive, int64(wid), 10)
+			}
+			if afterIndex && ok {
+				goodArgNum = false
+			}
+		}
+
+		if i+1 < end && format[i] == '.' {
+			i++
+			if afterIndex {
+				goodArgNum = false
+			}
+			argNum, i, afterIndex, reordered = argNumber(format, i, argNum, len(args), &goodArgNum, reordered)
+			if i < end && format[i] == '*' {
+				i++
+				prec, ok, next := intFromArg(args, argNum)
+				argNum = next
+				if prec < 0 {
+					ok = false
+				}
+				if !ok {
+					buf = append(buf, fmtBadPrec...)
+				} else {
+					directive = append(directive, '.')
+					directive = strconv.AppendInt(directive, int64(prec), 10)
+				}
+				afterIndex = false
+			} else {
+				prec, _, next := parseNum(format, i, end)
+				i = next
+				directive = append(directive, '.')
+				directive = strconv.AppendInt(directive, int64(prec), 10)
+			}
+		}
+
+		if !afterIndex {
+			argNum, i, afterIndex, reordered = argNumber(format, i, argNum, len(args), &goodArgNum, reordered)
+		}
+
+		if i >= end {
+			buf = append(buf, fmtNoVerb...)
+			break
+		}
+
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+
+		switch {
+		case verb == '%':
+			buf = append(buf, '%')
+		case !goodArgNum:
+			buf = append(buf, "%!"...)
+			buf = utf8.AppendRune(buf, verb)
+			buf = append(buf, fmtBadIndex...)
+		case argNum >= len(args):
+			buf = append(buf, "%!"...)
+			buf = utf8.AppendRune(buf, verb)
+			buf = append(buf, fmtMissing...)
+		default:
+			directive = utf8.AppendRune(directive, verb)
+			buf = append(buf, colorizeFormatted(colors, string(directive), args[argNum])...)
+			argNum++
+		}
+	}
+	if !reordered && argNum < len(args) {
+		buf = append(buf, fmtExtra...)
+		for i, arg := range args[argNum:] {
+			if i > 0 {
+				buf = append(buf, ", "...)
+			}
+			if arg == nil {
+				buf = append(buf, "<nil>"...)
+				continue
+			}
+			buf = fmt.Appendf(buf, "%T=%v", arg, arg)
+		}
+		buf = append(buf, ')')
+	}
+	return string(buf)
+}
+
+// colorizeFormatted formats single argument with directive and colorizes result by type of argument.
+// Plain %v keeps colors of argument parts if colorizer renders it same way fmt does.
+func colorizeFormatted(colors Colorizer, directive string, arg interface{}) string {
+	text := fmt.Sprintf(directive, arg)
+	if directive == "%v" {
+		if colored := colors.ColorizeByType(arg); color.ClearCode(colored) == text {
+			return colored
+		}
+	}
+	kind := colorizer.Kind(arg)
+	return colors.ColorizeByKeys(text, colorizer.NewKey(colorizer.FG_KEY, kind), colorizer.NewKey(colorizer.BG_KEY, kind))
+}
+
+// argNumber reads explicit argument index like [3] as fmt does.
+func argNumber(format string, i, argNum, numArgs int, goodArgNum *bool, reordered bool) (int, int, bool, bool) {
+	if len(format) <= i || format[i] != '[' {
+		return argNum, i, false, reordered
+	}
+	index, wid, ok := parseArgNumber(format[i:])
+	if ok && 0 <= index && index < numArgs {
+		return index, i + wid, true, true
+	}
+	*goodArgNum = false
+	return argNum, i + wid, ok, true
+}
+
+func parseArgNumber(format string) (int, int, bool) {
+	if len(format) < 3 {
+		return 0, 1, false
+	}
+	for i := 1; i < len(format); i++ {
+		if format[i] == ']' {
+			index, ok, next := parseNum(format, 1, i)
+			if !ok || next != i {
+				return 0, i + 1, false
+			}
+			return index - 1, i + 1, true
+		}
+	}
+	return 0, 1, false
+}
+
+// tooLargeNum - fmt ignores widths and precisions of this magnitude.
+const tooLargeNum = 1e6
+
+func parseNum(s string, start, end int) (int, bool, int) {
+	if start >= end {
+		return 0, false, end
+	}
+	num, isNum, i := 0, false, start
+	for ; i < end && '0' <= s[i] && s[i] <= '9'; i++ {
+		if num > tooLargeNum || num < -tooLargeNum {
+			return 0, false, end
+		}
+		num = num*10 + int(s[i]-'0')
+		isNum = true
+	}
+	return num, isNum, i
+}
+
+// intFromArg reads width or precision from argument as fmt does.
+func intFromArg(args []interface{}, argNum int) (int, bool, int) {
+	if argNum >= len(args) {
+		return 0, false, argNum
+	}
+	num, isInt := args[argNum].(int)
+	if !isInt {
+		switch v := reflect.ValueOf(args[argNum]); v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n := v.Int(); int64(int(n)) == n {
+				num, isInt = int(n), true
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if n := v.Uint(); int64(n) >= 0 && uint64(int(n)) == n {
+				num, isInt = int(n), true
+			}
+		}
+	}
+	if num > tooLargeNum || num < -tooLargeNum {
+		num, isInt = 0, false
+	}
+	return num, isInt, argNum + 1
+}
+
+// withoutZero removes flag '0', fmt does not pad with zeros when negative width is taken from argument.
+func withoutZero(directive []byte) []byte {
+	kept := directive[:1]
+	for _, c := range directive[1:] {
+		if c != '0' {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
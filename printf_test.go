@@ -0,0 +1,87 @@
+package logman
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Galdoba/logman/colorizer"
+	"github.com/gookit/color"
+)
+
+type point struct {
+	X, Y int
+}
+
+type hexer int
+
+func (h hexer) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, "hexer(%c,%v)", verb, f.Flag('0'))
+}
+
+func TestColorSprintf(t *testing.T) {
+	color.ForceColor()
+	scheme := colorizer.DefaultScheme()
+	cases := []struct {
+		format string
+		args   []interface{}
+	}{
+		{"plain text", nil},
+		{"%v and %v", []interface{}{1, "two"}},
+		{"%d items, %s, %q", []interface{}{3, "name", "quoted"}},
+		{"%08.3f|%-8.2f|%+d|% d|%x|%X|%o|%b|%e|%g", []interface{}{3.14159, 2.5, 7, 7, 255, 255, 8, 5, 1234.5678, 0.000001}},
+		{"%5s|%-5s|%.2s|%c|%U|%t", []interface{}{"ab", "ab", "abcdef", 'x', 'x', true}},
+		{"100%% done %d%%", []interface{}{5}},
+		{"%*d|%-*d|%.*f|%*.*f", []interface{}{6, 42, 6, 42, 2, 3.14159, 8, 3, 2.5}},
+		{"%*d", []interface{}{-6, 42}},
+		{"%0*d", []interface{}{-6, 42}},
+		{"%*d", []interface{}{"x", 42}},
+		{"%.*d", []interface{}{-1, 42}},
+		{"%[2]d %[1]d %d", []interface{}{1, 2}},
+		{"%[3]*.[2]*[1]f", []interface{}{12.0, 2, 6}},
+		{"%[5]d %[x]d %[1]2d", []interface{}{1}},
+		{"%d %d %d", []interface{}{1}},
+		{"%d", []interface{}{1, "extra", nil}},
+		{"%d %!", []interface{}{1}},
+		{"%", nil},
+		{"%.", []interface{}{1}},
+		{"%z", []interface{}{1}},
+		{"%v %+v %#v %T", []interface{}{point{1, 2}, point{1, 2}, point{1, 2}, point{1, 2}}},
+		{"%v %v %v", []interface{}{[]int{1, 2}, map[string]int{"a": 1}, &point{3, 4}}},
+		{"%v %s %w", []interface{}{errors.New("boom"), time.Second, errors.New("wrapped")}},
+		{"%v %05v %x", []interface{}{nil, hexer(1), "hi"}},
+		{"%6.2v|%-10v|", []interface{}{"abcdef", true}},
+		{"юникод %v ✓ %q", []interface{}{"строка", "✓"}},
+	}
+	for _, c := range cases {
+		colored := colorSprintf(scheme, c.format, c.args)
+		if got, want := color.ClearCode(colored), fmt.Sprintf(c.format, c.args...); got != want {
+			t.Errorf("format %q: expected %q, got %q", c.format, want, got)
+		}
+	}
+	colored := colorSprintf(scheme, "%05d and %s", []interface{}{42, "text"})
+	if !strings.Contains(colored, "\x1b[") || !strings.Contains(colored, "00042") {
+		t.Errorf("expected colored zero padded number, got %q", colored)
+	}
+}
+
+func TestStdFormatMessageColored(t *testing.T) {
+	color.ForceColor()
+	scheme := colorizer.DefaultScheme()
+	msg := NewMessage("user %q has %03d items worth %.2f%%", "bob", 7, 12.5)
+	text, err := stdFormatMessage(msg, scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := stdFormatMessage(msg, nil)
+	if color.ClearCode(text) != plain || plain != `user "bob" has 007 items worth 12.50%` {
+		t.Errorf("colored text %q does not match %q", color.ClearCode(text), plain)
+	}
+	msg.SetField(keyMessage, "overridden")
+	text, _ = stdFormatMessage(msg, scheme)
+	if color.ClearCode(text) != "overridden" {
+		t.Errorf("expected overridden text, got %q", color.ClearCode(text))
+	}
+}
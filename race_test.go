@@ -0,0 +1,6 @@
+//go:build race
+
+package logman
+
+// raceEnabled - sync.Pool drops items randomly under race detector, so allocations are not counted.
+const raceEnabled = true
@@ -0,0 +1,350 @@
+package logman
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// RedactMask replaces redacted data by default.
+const RedactMask = "[REDACTED]"
+
+// Detector is built-in detector of secrets and personal data.
+type Detector int
+
+const (
+	// DetectBearer finds tokens of "Bearer <token>" credentials.
+	DetectBearer Detector = iota
+	// DetectAWSKey finds AWS access key ids and labeled secret access keys.
+	DetectAWSKey
+	// DetectCreditCard finds card numbers (13-19 digits, spaces or dashes allowed) passing Luhn check.
+	DetectCreditCard
+	// DetectEmail finds email addresses.
+	DetectEmail
+	// DetectIP finds IPv4 and IPv6 addresses.
+	DetectIP
+)
+
+// DefaultRedactedFields are names of fields redacted by Redactor with no RedactFields option.
+var DefaultRedactedFields = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey"}
+
+var (
+	reBearer     = regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	reAWSKeyID   = regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16}\b`)
+	reAWSSecret  = regexp.MustCompile(`(?i)\b(aws_?secret_?access_?key\s*[=:]\s*)[A-Za-z0-9/+=]{40}`)
+	reCreditCard = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	reEmail      = regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`)
+	reIPv4       = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	reIPv6       = regexp.MustCompile(`(?i)[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}`)
+)
+
+// redactSettings describes Redactor.
+type redactSettings struct {
+	mask      string
+	detectors []Detector
+	patterns  []*regexp.Regexp
+	fields    []string
+}
+
+// RedactOption - settings of Redactor.
+type RedactOption func(*redactSettings)
+
+// RedactDetectors sets built-in detectors used by Redactor (all of them by default).
+func RedactDetectors(detectors ...Detector) RedactOption {
+	return func(rs *redactSettings) {
+		rs.detectors = detectors
+	}
+}
+
+// RedactPatterns adds regular expressions which matches are redacted.
+func RedactPatterns(patterns ...*regexp.Regexp) RedactOption {
+	return func(rs *redactSettings) {
+		rs.patterns = append(rs.patterns, patterns...)
+	}
+}
+
+// RedactFields sets names of fields which values are always redacted (DefaultRedactedFields by default).
+// Names are case insensitive. Values following these names with "=" or ":" in message text are redacted too.
+func RedactFields(names ...string) RedactOption {
+	return func(rs *redactSettings) {
+		rs.fields = names
+	}
+}
+
+// RedactWith sets text replacing redacted data (RedactMask by default).
+func RedactWith(mask string) RedactOption {
+	return func(rs *redactSettings) {
+		rs.mask = mask
+	}
+}
+
+// WithRedaction - redacts message text and fields of every message before any formatter
+// or writer sees them. Redaction follows hooks, so fields set by hooks are redacted too.
+// Message is redacted when first writer of level accepts it: messages vetoed by hooks
+// or filtered out by every writer are never rendered for redaction.
+func WithRedaction(opts ...RedactOption) LogmanOptions {
+	redactor := NewRedactor(opts...)
+	return func(o *options) {
+		o.redactor = redactor
+	}
+}
+
+// Redactor replaces secrets and personal data in messages. It is Hook.
+type Redactor struct {
+	mask     string
+	replaces []func(string) string
+	fields   map[string]bool
+}
+
+// NewRedactor creates Redactor.
+func NewRedactor(opts ...RedactOption) *Redactor {
+	rs := redactSettings{
+		mask:      RedactMask,
+		detectors: []Detector{DetectBearer, DetectAWSKey, DetectCreditCard, DetectEmail, DetectIP},
+		fields:    DefaultRedactedFields,
+	}
+	for _, set := range opts {
+		set(&rs)
+	}
+	r := &Redactor{mask: rs.mask, fields: make(map[string]bool)}
+	for _, detector := range rs.detectors {
+		r.replaces = append(r.replaces, r.detector(detector)...)
+	}
+	for _, re := range rs.patterns {
+		re := re
+		r.replaces = append(r.replaces, func(text string) string {
+			return re.ReplaceAllLiteralString(text, r.mask)
+		})
+	}
+	names := []string{}
+	for _, name := range rs.fields {
+		r.fields[strings.ToLower(name)] = true
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	if len(names) > 0 {
+		reNamed := regexp.MustCompile(`(?i)\b(` + strings.Join(names, "|") + `)(\s*[=:]\s*)("[^"]*"|(?:bearer|basic)\s+\S+|\S+)`)
+		r.replaces = append(r.replaces, func(text string) string {
+			return reNamed.ReplaceAllString(text, "${1}${2}"+escapeTemplate(r.mask))
+		})
+	}
+	return r
+}
+
+func (r *Redactor) detector(detector Detector) []func(string) string {
+	mask := escapeTemplate(r.mask)
+	switch detector {
+	case DetectBearer:
+		return []func(string) string{func(text string) string {
+			return reBearer.ReplaceAllString(text, "${1}"+mask)
+		}}
+	case DetectAWSKey:
+		return []func(string) string{
+			func(text string) string {
+				return reAWSKeyID.ReplaceAllLiteralString(text, r.mask)
+			},
+			func(text string) string {
+				return reAWSSecret.ReplaceAllString(text, "${1}"+mask)
+			},
+		}
+	case DetectCreditCard:
+		return []func(string) string{r.verified(reCreditCard, luhnValid)}
+	case DetectEmail:
+		return []func(string) string{func(text string) string {
+			return reEmail.ReplaceAllLiteralString(text, r.mask)
+		}}
+	case DetectIP:
+		isIP := func(s string) bool { return net.ParseIP(s) != nil }
+		return []func(string) string{r.verified(reIPv4, isIP), r.verified(reIPv6, isIP)}
+	}
+	return nil
+}
+
+// verified redacts matches of re passing check and not adjacent to letters, digits or colons.
+func (r *Redactor) verified(re *regexp.Regexp, check func(string) bool) func(string) string {
+	return func(text string) string {
+		matches := re.FindAllStringIndex(text, -1)
+		if matches == nil {
+			return text
+		}
+		sb := strings.Builder{}
+		last := 0
+		for _, m := range matches {
+			if !check(text[m[0]:m[1]]) || (m[0] > 0 && isAdjacent(text[m[0]-1])) || (m[1] < len(text) && isAdjacent(text[m[1]])) {
+				continue
+			}
+			sb.WriteString(text[last:m[0]])
+			sb.WriteString(r.mask)
+			last = m[1]
+		}
+		sb.WriteString(text[last:])
+		return sb.String()
+	}
+}
+
+func isAdjacent(c byte) bool {
+	return c == ':' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// luhnValid reports whether card number (digits with optional spaces or dashes) passes Luhn check.
+func luhnValid(number string) bool {
+	sum, digits := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
+
+func escapeTemplate(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+// Redact returns text with all detected data replaced by mask.
+func (r *Redactor) Redact(text string) string {
+	for _, replace := range r.replaces {
+		text = replace(text)
+	}
+	return text
+}
+
+// Before redacts rendered text of message and its fields. If text is redacted
+// format arguments of message are replaced by redacted text. Redacted fields which are
+// not strings are replaced with RedactedValue, errors are redacted by every error of wrap chain.
+// Without detectors and patterns only fields are redacted by name and text is not rendered.
+func (r *Redactor) Before(_ string, msg Message) Message {
+	inspect := len(r.replaces) > 0
+	if inspect {
+		r.redactText(msg)
+	}
+	for _, key := range msg.Fields() {
+		switch key {
+		case keyTime, keyLevel:
+			continue
+		}
+		value := msg.Value(key)
+		if value == nil {
+			continue
+		}
+		if r.fields[strings.ToLower(key)] {
+			if _, ok := value.(string); ok {
+				msg.SetField(key, r.mask)
+			} else {
+				msg.SetField(key, r.placeholder(value))
+			}
+			continue
+		}
+		if !inspect {
+			continue
+		}
+		if lv, ok := value.(*LazyValue); ok {
+			value = lv.Resolve()
+			msg.SetField(key, value)
+		}
+		if redacted, ok := r.redactValue(value); ok {
+			msg.SetField(key, redacted)
+		}
+	}
+	return msg
+}
+
+func (r *Redactor) redactText(msg Message) {
+	if m, ok := msg.(*message); ok {
+		text := m.text()
+		if redacted := r.Redact(text); redacted != text {
+			m.setText(redacted)
+		}
+		return
+	}
+	inputs := msg.InputArgs()
+	if inputs == nil {
+		return
+	}
+	format, ok := inputs[-1].(string)
+	if !ok {
+		return
+	}
+	args := make([]interface{}, 0, len(inputs)-1)
+	for i := 0; i < len(inputs)-1; i++ {
+		args = append(args, inputs[i])
+	}
+	text := fmt.Sprintf(format, args...)
+	if redacted := r.Redact(text); redacted != text {
+		for i := range inputs {
+			delete(inputs, i)
+		}
+		inputs[-1] = strings.ReplaceAll(redacted, "%", "%%")
+		msg.SetField(keyMessage, redacted)
+	}
+}
+
+// RedactedValue replaces value of field which is not string when Redactor redacts it.
+// It is printed as mask and keeps name of type of value replaced.
+type RedactedValue struct {
+	Type string
+	mask string
+}
+
+// String returns mask.
+func (rv RedactedValue) String() string {
+	return rv.mask
+}
+
+func (r *Redactor) placeholder(value interface{}) RedactedValue {
+	return RedactedValue{Type: fmt.Sprintf("%T", value), mask: r.mask}
+}
+
+// redactValue returns redacted value and true if value has data to redact.
+// Strings are redacted in place, errors are redacted by every error of wrap chain
+// and other values are replaced with RedactedValue.
+func (r *Redactor) redactValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		redacted := r.Redact(v)
+		return redacted, redacted != v
+	case *ErrorValue:
+		return r.redactError(v)
+	case error:
+		return r.redactError(&ErrorValue{err: v})
+	}
+	if text := fmt.Sprint(value); r.Redact(text) != text {
+		return r.placeholder(value), true
+	}
+	return value, false
+}
+
+// redactError returns ErrorValue with redacted messages of wrap chain, error kept is not changed.
+func (r *Redactor) redactError(ev *ErrorValue) (interface{}, bool) {
+	tree, changed := r.redactNode(ev.Tree())
+	if !changed {
+		return ev, false
+	}
+	return &ErrorValue{err: ev.err, stack: ev.stack, redacted: &tree}, true
+}
+
+func (r *Redactor) redactNode(node ErrorNode) (ErrorNode, bool) {
+	redacted := r.Redact(node.Message)
+	changed := redacted != node.Message
+	node.Message = redacted
+	if len(node.Causes) > 0 {
+		causes := make([]ErrorNode, len(node.Causes))
+		for i, cause := range node.Causes {
+			var causeChanged bool
+			causes[i], causeChanged = r.redactNode(cause)
+			changed = changed || causeChanged
+		}
+		node.Causes = causes
+	}
+	return node, changed
+}
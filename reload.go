@@ -0,0 +1,303 @@
+package logman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// snapshot is configuration of Logger published with single atomic store:
+// level table, minimum importance, verbosity rules, samplers, deduplicators and writers. Every message is prepared and
+// written against one snapshot.
+type snapshot struct {
+	levels   levelTable
+	minimum  int
+	rules    *verbosityRules
+	samplers map[string]*sampler
+	dedups   map[string]*deduplicator
+	epoch    *epoch
+}
+
+// epoch is writer registry shared by snapshots until Reload replaces it.
+// It counts messages in flight, so writers removed by Reload are closed
+// only after last message prepared with them is written.
+type epoch struct {
+	writers *writerRegistry
+	refs    atomic.Int64
+	retired atomic.Bool
+	idle    chan struct{}
+	once    sync.Once
+}
+
+func newEpoch(writers *writerRegistry) *epoch {
+	return &epoch{writers: writers, idle: make(chan struct{})}
+}
+
+// acquire returns current snapshot and holds its epoch until delivery is released.
+func (l *Logger) acquire() *snapshot {
+	for {
+		snap := l.state.Load()
+		snap.epoch.refs.Add(1)
+		if l.state.Load().epoch == snap.epoch {
+			return snap
+		}
+		snap.epoch.release()
+	}
+}
+
+func (e *epoch) release() {
+	if e.refs.Add(-1) == 0 && e.retired.Load() {
+		e.once.Do(func() { close(e.idle) })
+	}
+}
+
+// retire marks epoch as replaced. Returned channel is closed when no message holds it.
+func (e *epoch) retire() <-chan struct{} {
+	e.retired.Store(true)
+	if e.refs.Load() == 0 {
+		e.once.Do(func() { close(e.idle) })
+	}
+	return e.idle
+}
+
+// Reload atomically replaces levels, writers, formatters, minimum importance, verbosity rules,
+// samplers and deduplicators of l with ones built from options. Temporary importance override is cancelled. Application name, async settings, context
+// extractors and hooks are kept as they were set by New.
+// Writers used by new levels which are already opened are kept, writers registered
+// by name in options are opened anew and writers which are no longer used are closed
+// after messages in flight are written to them: by Reload if there are none, otherwise
+// in background, so Reload may be called by hooks and writers. Close waits for writers
+// closed in background, their errors are printed to stderr.
+// If options are invalid or writer can not be opened, current configuration is kept
+// and error is returned.
+func (l *Logger) Reload(opts ...LogmanOptions) error {
+	opt := defaultOpts()
+	for _, set := range opts {
+		set(&opt)
+	}
+	levels, err := opt.levelTable()
+	if err != nil {
+		return fmt.Errorf("reload failed: %v", err)
+	}
+	rules, err := parseVerbosity(opt.verbosity, levels.importances())
+	if err != nil {
+		return fmt.Errorf("reload failed: %v", err)
+	}
+	samplers, err := opt.samplers(levels)
+	if err != nil {
+		return fmt.Errorf("reload failed: %v", err)
+	}
+	dedups, err := opt.dedups(levels)
+	if err != nil {
+		return fmt.Errorf("reload failed: %v", err)
+	}
+	l.mu.Lock()
+	current := l.state.Load()
+	writers, adopted, err := current.epoch.writers.successor(&opt, levels)
+	if err != nil {
+		l.mu.Unlock()
+		return fmt.Errorf("reload failed: %v", err)
+	}
+	l.cancelOverride()
+	summaries := haltSamplers(current.samplers)
+	l.state.Store(&snapshot{levels: levels, minimum: opt.appMinimumLoglevel, rules: rules, samplers: samplers, dedups: dedups, epoch: newEpoch(writers)})
+	startSamplers(l, samplers)
+	l.mu.Unlock()
+	// summaries of replaced samplers are processed with new configuration
+	emitSummaries(l, summaries)
+	idle := current.epoch.retire()
+	select {
+	case <-idle:
+		return l.retireSnapshot(current, adopted)
+	default:
+	}
+	// message in flight may be the one which hook or writer calls Reload for,
+	// waiting for it here would never end.
+	l.retiring.Add(1)
+	go func() {
+		defer l.retiring.Done()
+		<-idle
+		if err := l.retireSnapshot(current, adopted); err != nil {
+			fmt.Fprintf(os.Stderr, "logman: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// retireSnapshot reports repeats of replaced snapshot and closes its writers not adopted by new one.
+func (l *Logger) retireSnapshot(snap *snapshot, adopted map[string]bool) error {
+	flushDedups(l, snap)
+	if err := snap.epoch.writers.closeExcept(adopted); err != nil {
+		return fmt.Errorf("reload: %v", err)
+	}
+	return nil
+}
+
+// Reload replaces configuration of default Logger (see Logger.Reload).
+func Reload(opts ...LogmanOptions) error {
+	return Default().Reload(opts...)
+}
+
+// ReloadConfig reloads l from configuration file (see LoadConfig and Reload).
+func (l *Logger) ReloadConfig(path string) error {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return fmt.Errorf("reload failed: %v", err)
+	}
+	opts, err := cfg.Options()
+	if err != nil {
+		return fmt.Errorf("reload failed: %v", err)
+	}
+	return l.Reload(opts...)
+}
+
+// WatchConfig starts reloading l from configuration file (see ReloadConfig) when file
+// changes or process receives SIGHUP. File is checked every interval (1 second if not positive).
+// Failed reload keeps current configuration and is passed to report
+// (printed to stderr if report is nil). Watching stops when ctx is done.
+func (l *Logger) WatchConfig(ctx context.Context, path string, interval time.Duration, report func(error)) {
+	if report == nil {
+		report = func(err error) {
+			fmt.Fprintf(os.Stderr, "logman: %v\n", err)
+		}
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	last := configStamp(path)
+	go func() {
+		defer signal.Stop(hangup)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				last = configStamp(path)
+			case <-ticker.C:
+				stamp := configStamp(path)
+				if stamp == last {
+					continue
+				}
+				last = stamp
+			}
+			if err := l.ReloadConfig(path); err != nil {
+				report(err)
+			}
+		}
+	}()
+}
+
+// WatchConfig starts reloading default Logger from configuration file (see Logger.WatchConfig).
+func WatchConfig(ctx context.Context, path string, interval time.Duration, report func(error)) {
+	Default().WatchConfig(ctx, path, interval, report)
+}
+
+// configStamp identifies version of configuration file by modification time and size.
+func configStamp(path string) string {
+	if path == "" {
+		path = os.Getenv(EnvConfig)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%v/%v", info.ModTime().UnixNano(), info.Size())
+}
+
+// successor creates registry for levels of reloaded Logger. Writers registered by name
+// in opt are opened anew, other writers used by levels are taken from r or opened.
+// It returns keys of writers taken from r, so they are not closed with it.
+func (r *writerRegistry) successor(opt *options, levels levelTable) (*writerRegistry, map[string]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return nil, nil, errWritersClosed
+	}
+	next, adopted, err := opt.openWriters(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, dest := range next.destinations {
+		if adopted[name] {
+			continue
+		}
+		if old, ok := r.destinations[name]; ok && sameStream(old, dest) {
+			next.destinations[name] = old
+			adopted[name] = true
+		}
+	}
+	for _, lvl := range levels {
+		for key := range lvl.writerFormatterMap {
+			if _, ok := next.destinations[key]; ok {
+				continue
+			}
+			if dest, ok := r.destinations[key]; ok && keepable(dest, r.segments, next.segments) {
+				next.destinations[key] = dest
+				adopted[key] = true
+				continue
+			}
+			dest, err := openDestination(key, next.segments)
+			if err != nil {
+				next.closeExcept(adopted)
+				return nil, nil, err
+			}
+			next.destinations[key] = dest
+		}
+	}
+	return next, adopted, nil
+}
+
+// keepable reports whether opened destination can serve reloaded Logger.
+// Directories are reopened when segment settings change.
+func keepable(dest destination, current, next segmentSettings) bool {
+	if _, ok := dest.(*dirDestination); ok {
+		return current == next
+	}
+	return true
+}
+
+// sameStream reports whether both destinations write to the same io.Writer,
+// so registering writer again does not close it.
+func sameStream(a, b destination) bool {
+	sa, ok := a.(*streamDestination)
+	if !ok {
+		return false
+	}
+	sb, ok := b.(*streamDestination)
+	if !ok || !reflect.TypeOf(sa.writer).Comparable() {
+		return false
+	}
+	return sa.writer == sb.writer
+}
+
+// opened returns destination of r opened by named writer with the same identity or nil.
+// r must be locked by caller.
+func (r *writerRegistry) opened(named namedWriter) destination {
+	if r == nil || named.identity == "" {
+		return nil
+	}
+	if sd, ok := r.destinations[named.name].(*streamDestination); ok && sd.identity == named.identity {
+		return sd
+	}
+	return nil
+}
+
+// closeExcept closes registry and all its destinations except kept ones.
+func (r *writerRegistry) closeExcept(kept map[string]bool) error {
+	r.mu.Lock()
+	for key := range kept {
+		delete(r.destinations, key)
+	}
+	r.mu.Unlock()
+	return r.Close()
+}
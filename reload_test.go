@@ -0,0 +1,255 @@
+package logman
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// closeTracker is buffer reporting writes after Close.
+type closeTracker struct {
+	lockedBuffer
+	closed bool
+}
+
+func (c *closeTracker) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return 0, errors.New("write after close")
+	}
+	return c.lockedBuffer.Write(p)
+}
+
+func (c *closeTracker) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func TestReload(t *testing.T) {
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	for _, async := range []bool{false, true} {
+		bufA, bufB := &closeTracker{}, &closeTracker{}
+		opts := []LogmanOptions{WithLogLevels(NewLoggingLevel(INFO)), WithWriterNamed("a", bufA, formatter)}
+		if async {
+			opts = append(opts, WithAsync(64, 2, OverflowBlock))
+		}
+		lgr, err := New(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg := sync.WaitGroup{}
+		started := make(chan struct{}, 8)
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					if i == 10 {
+						started <- struct{}{}
+					}
+					if err := lgr.Info("message %v", i); err != nil {
+						t.Error(err)
+					}
+				}
+			}()
+		}
+		for g := 0; g < 8; g++ {
+			<-started
+		}
+		err = lgr.Reload(WithLogLevels(NewLoggingLevel(INFO)), WithWriterNamed("b", bufB, formatter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Wait()
+		if err := lgr.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if !bufA.closed {
+			t.Errorf("async=%v: removed writer is not closed", async)
+		}
+		linesA, linesB := strings.Count(bufA.String(), "\n"), strings.Count(bufB.String(), "\n")
+		if linesA+linesB != 4000 || linesA < 80 {
+			t.Errorf("async=%v: expected 4000 messages, have %v before and %v after reload", async, linesA, linesB)
+		}
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "logman.json")
+	logA, logB := filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")
+	config := func(path string) string {
+		return `{"levels": [{"name": "info"}], "writers": [{"key": "` + filepath.ToSlash(path) + `", "fields": "message_only"}]}`
+	}
+	if err := os.WriteFile(configPath, []byte(config(logA)), 0666); err != nil {
+		t.Fatal(err)
+	}
+	lgr, err := NewFromConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lgr.Close(context.Background())
+
+	if err := os.WriteFile(configPath, []byte(`{"levels": [{"name": "info", "exit": "no"}]}`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := lgr.ReloadConfig(configPath); err == nil {
+		t.Errorf("expected error reloading invalid config")
+	}
+	lgr.Info("kept")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reports := make(chan error, 10)
+	lgr.WatchConfig(ctx, configPath, 10*time.Millisecond, func(err error) { reports <- err })
+	// file is replaced at once, so watcher never reads it half written
+	if err := os.WriteFile(configPath+".tmp", []byte(config(logB)+"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(configPath+".tmp", configPath); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for lgr.levels()[INFO].writerFormatterMap[filepath.ToSlash(logB)] == nil {
+		select {
+		case err := <-reports:
+			t.Fatal(err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("config change was not picked up")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	lgr.Info("reloaded")
+	for path, expected := range map[string]string{logA: "kept \n", logB: "reloaded \n"} {
+		bt, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bt) != expected {
+			t.Errorf("%v: expected %q, have %q", filepath.Base(path), expected, string(bt))
+		}
+	}
+}
+
+func TestReloadFromHook(t *testing.T) {
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	old, next := &closeTracker{}, &closeTracker{}
+	var lgr *Logger
+	reloaded := make(chan error, 1)
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO)),
+		WithWriterNamed("old", old, formatter),
+		WithHooks(HookFunc(func(_ string, msg Message) Message {
+			if msg.Value("reload") != nil {
+				reloaded <- lgr.Reload(WithLogLevels(NewLoggingLevel(INFO)), WithWriterNamed("next", next, formatter))
+			}
+			return msg
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lgr.With(NewField("reload", true)).Info("reloading")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reload called by hook deadlocks")
+	}
+	if err := <-reloaded; err != nil {
+		t.Fatal(err)
+	}
+	lgr.Info("reloaded")
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if old.String() != "reloading \n" || !old.closed {
+		t.Errorf("replaced writer: closed %v, output %q", old.closed, old.String())
+	}
+	if next.String() != "reloaded \n" {
+		t.Errorf("unexpected output of new writer %q", next.String())
+	}
+}
+
+func TestReloadKeepsRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	opts := func(maxSize int64) []LogmanOptions {
+		return []LogmanOptions{
+			WithLogLevels(NewLoggingLevel(INFO)),
+			WithRotatingFile(path, RotationMaxSize(maxSize)),
+			WithGlobalWriterFormatter(path, formatter),
+		}
+	}
+	lgr, err := New(opts(512)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	destination := func() destination {
+		return lgr.state.Load().epoch.writers.destinations[path]
+	}
+	opened := destination()
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				if err := lgr.Info("message %03d", i); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		if err := lgr.Reload(opts(512)...); err != nil {
+			t.Fatal(err)
+		}
+		if destination() != opened {
+			t.Fatalf("rotating file with the same settings is opened again by Reload")
+		}
+	}
+	wg.Wait()
+	if err := lgr.Reload(opts(1024)...); err != nil {
+		t.Fatal(err)
+	}
+	if destination() == opened {
+		t.Errorf("rotating file with changed settings is kept by Reload")
+	}
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := 0
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Name() != "app.log" && len(content) > 512 {
+			t.Errorf("rotated file %v exceeds size limit: %v bytes", entry.Name(), len(content))
+		}
+		lines += strings.Count(string(content), "\n")
+	}
+	if lines != 800 {
+		t.Errorf("expected 800 messages, have %v", lines)
+	}
+}
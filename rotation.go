@@ -0,0 +1,413 @@
+package logman
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRotationTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFile is a file writer that rolls over by size and/or time boundaries.
+// Rotated files are named '<name>-<timestamp><ext>' next to active file,
+// optionally gzipped in background and removed by retention rules.
+// RotatingFile is safe for concurrent use.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	period     time.Duration
+	timeFormat string
+	keep       int
+	maxAge     time.Duration
+	compress   bool
+	now        func() time.Time
+	openFile   func(name string, flag int, perm os.FileMode) (*os.File, error)
+
+	mu           sync.Mutex
+	file         *os.File
+	size         int64
+	nextRotation time.Time
+	background   sync.WaitGroup
+	backgroundMu sync.Mutex
+	closed       bool
+
+	errMu         sync.Mutex
+	backgroundErr error
+}
+
+// RotationOption - settings for RotatingFile.
+type RotationOption func(*RotatingFile)
+
+// RotationMaxSize sets size in bytes after which file is rotated. 0 disables size rotation.
+func RotationMaxSize(bytes int64) RotationOption {
+	return func(rf *RotatingFile) {
+		rf.maxSize = bytes
+	}
+}
+
+// RotationPeriod sets time based rotation. File is rotated at period boundaries:
+// periods multiple of 24h are aligned to local midnight, others to time.Truncate(period).
+// Use time.Hour for hourly and 24*time.Hour for daily rotation. 0 disables time rotation.
+func RotationPeriod(period time.Duration) RotationOption {
+	return func(rf *RotatingFile) {
+		rf.period = period
+	}
+}
+
+// RotationTimeFormat sets time layout used in names of rotated files.
+func RotationTimeFormat(layout string) RotationOption {
+	return func(rf *RotatingFile) {
+		rf.timeFormat = layout
+	}
+}
+
+// RotationKeep sets number of rotated files to keep. 0 keeps all.
+func RotationKeep(generations int) RotationOption {
+	return func(rf *RotatingFile) {
+		rf.keep = generations
+	}
+}
+
+// RotationMaxAge sets maximum age of rotated files. 0 keeps files of any age.
+func RotationMaxAge(age time.Duration) RotationOption {
+	return func(rf *RotatingFile) {
+		rf.maxAge = age
+	}
+}
+
+// RotationCompress enables gzip compression of rotated files in background.
+func RotationCompress(compress bool) RotationOption {
+	return func(rf *RotatingFile) {
+		rf.compress = compress
+	}
+}
+
+// NewRotatingFile opens (or creates) file at path and returns RotatingFile writing to it.
+func NewRotatingFile(path string, opts ...RotationOption) (*RotatingFile, error) {
+	rf := RotatingFile{
+		path:       path,
+		timeFormat: defaultRotationTimeFormat,
+		now:        time.Now,
+		openFile:   os.OpenFile,
+	}
+	for _, set := range opts {
+		set(&rf)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return nil, fmt.Errorf("failed to create directory for '%v': %v", path, err)
+	}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return &rf, nil
+}
+
+// WithRotatingFile - registers RotatingFile under its path, so path can be used
+// as writer key in WithWriter and WithGlobalWriterFormatter.
+// Reload with the same path and settings keeps RotatingFile opened before.
+func WithRotatingFile(path string, opts ...RotationOption) LogmanOptions {
+	settings := RotatingFile{path: path, timeFormat: defaultRotationTimeFormat}
+	for _, set := range opts {
+		set(&settings)
+	}
+	identity := fmt.Sprintf("rotating %q size=%v period=%v format=%q keep=%v age=%v compress=%v",
+		path, settings.maxSize, settings.period, settings.timeFormat, settings.keep, settings.maxAge, settings.compress)
+	return func(o *options) {
+		o.namedWriters = append(o.namedWriters, namedWriter{
+			name:     path,
+			identity: identity,
+			open: func() (destination, error) {
+				rf, err := NewRotatingFile(path, opts...)
+				if err != nil {
+					return nil, err
+				}
+				return &streamDestination{writer: rf, identity: identity}, nil
+			},
+		})
+	}
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := rf.openFile(rf.path, flags, perm)
+	if err != nil {
+		return fmt.Errorf("failed to open rotating file '%v': %v", rf.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat rotating file '%v': %v", rf.path, err)
+	}
+	rf.file = f
+	rf.size = info.Size()
+	rf.nextRotation = rf.boundaryAfter(rf.now())
+	return nil
+}
+
+// boundaryAfter returns next time boundary after t or zero time if time rotation is disabled.
+func (rf *RotatingFile) boundaryAfter(t time.Time) time.Time {
+	if rf.period <= 0 {
+		return time.Time{}
+	}
+	day := 24 * time.Hour
+	if rf.period%day == 0 {
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return midnight.AddDate(0, 0, int(rf.period/day))
+	}
+	return t.Truncate(rf.period).Add(rf.period)
+}
+
+// Write writes p to active file rotating it first if needed.
+// If rotation fails p is still written to active file and error of rotation is returned.
+// Errors of background compression are returned by following Write, Sync or Close.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.closed {
+		return 0, fmt.Errorf("rotating file '%v' is closed", rf.path)
+	}
+	var errRotation error
+	if rf.mustRotate(len(p)) {
+		errRotation = rf.rotate()
+	}
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	if errRotation != nil {
+		return n, errRotation
+	}
+	return n, rf.takeBackgroundErr()
+}
+
+func (rf *RotatingFile) mustRotate(incoming int) bool {
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(incoming) > rf.maxSize {
+		return true
+	}
+	if !rf.nextRotation.IsZero() && !rf.now().Before(rf.nextRotation) {
+		return true
+	}
+	return false
+}
+
+// Rotate forces rotation of active file.
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.closed {
+		return fmt.Errorf("rotating file '%v' is closed", rf.path)
+	}
+	return rf.rotate()
+}
+
+// rotate renames active file and opens new one. If it fails file at path is
+// reopened, so writes continue to it.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return rf.reopen(fmt.Errorf("failed to close rotating file '%v': %v", rf.path, err))
+	}
+	rotated := rf.rotatedName(rf.now())
+	if err := os.Rename(rf.path, rotated); err != nil {
+		return rf.reopen(fmt.Errorf("failed to rotate file '%v': %v", rf.path, err))
+	}
+	if err := rf.open(); err != nil {
+		// rotated file is moved back, so messages keep going to file at path
+		if errBack := os.Rename(rotated, rf.path); errBack != nil {
+			err = joinErrors("rotation failed", err, fmt.Errorf("failed to restore rotating file '%v': %v", rf.path, errBack))
+		}
+		return rf.reopen(err)
+	}
+	rf.background.Add(1)
+	go func() {
+		defer rf.background.Done()
+		rf.backgroundMu.Lock()
+		defer rf.backgroundMu.Unlock()
+		if rf.compress {
+			// rotated file may be already removed by retention of previous rotation
+			if err := compressFile(rotated); err != nil && !os.IsNotExist(err) {
+				rf.setBackgroundErr(fmt.Errorf("failed to compress rotated file '%v': %v", rotated, err))
+			}
+		}
+		rf.removeExpired()
+	}()
+	return nil
+}
+
+// reopen opens file at path in append mode after failed rotation.
+func (rf *RotatingFile) reopen(errRotation error) error {
+	if err := rf.open(); err != nil {
+		return joinErrors("rotation failed", errRotation, err)
+	}
+	return errRotation
+}
+
+func (rf *RotatingFile) setBackgroundErr(err error) {
+	rf.errMu.Lock()
+	defer rf.errMu.Unlock()
+	if rf.backgroundErr == nil {
+		rf.backgroundErr = err
+	}
+}
+
+func (rf *RotatingFile) takeBackgroundErr() error {
+	rf.errMu.Lock()
+	defer rf.errMu.Unlock()
+	err := rf.backgroundErr
+	rf.backgroundErr = nil
+	return err
+}
+
+func (rf *RotatingFile) nameParts() (string, string) {
+	ext := filepath.Ext(rf.path)
+	return strings.TrimSuffix(rf.path, ext), ext
+}
+
+func (rf *RotatingFile) rotatedName(t time.Time) string {
+	base, ext := rf.nameParts()
+	name := fmt.Sprintf("%v-%v%v", base, t.Format(rf.timeFormat), ext)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			if _, err := os.Stat(name + ".gz"); os.IsNotExist(err) {
+				return name
+			}
+		}
+		name = fmt.Sprintf("%v-%v.%v%v", base, t.Format(rf.timeFormat), i, ext)
+	}
+}
+
+// rotatedFiles returns rotated files sorted from newest to oldest.
+func (rf *RotatingFile) rotatedFiles() []os.FileInfo {
+	base, ext := rf.nameParts()
+	prefix := filepath.Base(base) + "-"
+	entries, err := os.ReadDir(filepath.Dir(rf.path))
+	if err != nil {
+		return nil
+	}
+	files := []os.FileInfo{}
+	for _, entry := range entries {
+		if !rf.isRotatedName(entry.Name(), prefix, ext) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, info)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].ModTime().Equal(files[j].ModTime()) {
+			return files[i].Name() > files[j].Name()
+		}
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+	return files
+}
+
+// isRotatedName reports whether name is '<prefix><timestamp>[.N]<ext>[.gz]', so files
+// which only share prefix with active file are never taken for rotated ones.
+func (rf *RotatingFile) isRotatedName(name, prefix, ext string) bool {
+	name = strings.TrimSuffix(name, ".gz")
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+		return false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+	if _, err := time.Parse(rf.timeFormat, stamp); err == nil {
+		return true
+	}
+	dot := strings.LastIndexByte(stamp, '.')
+	if dot < 0 {
+		return false
+	}
+	if _, err := strconv.Atoi(stamp[dot+1:]); err != nil {
+		return false
+	}
+	_, err := time.Parse(rf.timeFormat, stamp[:dot])
+	return err == nil
+}
+
+func (rf *RotatingFile) removeExpired() {
+	if rf.keep <= 0 && rf.maxAge <= 0 {
+		return
+	}
+	dir := filepath.Dir(rf.path)
+	for i, info := range rf.rotatedFiles() {
+		expired := rf.keep > 0 && i >= rf.keep
+		if rf.maxAge > 0 && rf.now().Sub(info.ModTime()) > rf.maxAge {
+			expired = true
+		}
+		if expired {
+			os.Remove(filepath.Join(dir, info.Name()))
+		}
+	}
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		zw.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	os.Chtimes(path+".gz", info.ModTime(), info.ModTime())
+	return os.Remove(path)
+}
+
+// Sync commits content of active file to stable storage.
+func (rf *RotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.closed {
+		return nil
+	}
+	if err := rf.file.Sync(); err != nil {
+		return err
+	}
+	return rf.takeBackgroundErr()
+}
+
+// Close closes active file and waits for background compression and cleanup.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	if rf.closed {
+		rf.mu.Unlock()
+		return nil
+	}
+	rf.closed = true
+	err := rf.file.Close()
+	rf.mu.Unlock()
+	rf.background.Wait()
+	if err != nil {
+		return err
+	}
+	return rf.takeBackgroundErr()
+}
@@ -0,0 +1,202 @@
+package logman
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO)),
+		WithRotatingFile(path, RotationMaxSize(100), RotationKeep(2), RotationCompress(true)),
+		WithGlobalWriterFormatter(path, formatter),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		if err := lgr.Info("message number %03d", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated := 0
+	for _, entry := range entries {
+		switch {
+		case entry.Name() == "app.log":
+		case strings.HasPrefix(entry.Name(), "app-") && strings.HasSuffix(entry.Name(), ".log.gz"):
+			rotated++
+		default:
+			t.Errorf("unexpected file %v", entry.Name())
+		}
+	}
+	if rotated != 2 {
+		t.Errorf("expected 2 rotated files kept, have %v", rotated)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 100 {
+		t.Errorf("active file exceeds max size: %v", info.Size())
+	}
+}
+
+func TestRotatingFileByTime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "daily.log")
+	clock := time.Date(2024, 5, 1, 23, 59, 0, 0, time.Local)
+	rf, err := NewRotatingFile(path, RotationPeriod(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rf.now = func() time.Time { return clock }
+	rf.nextRotation = rf.boundaryAfter(clock)
+	if _, err := rf.Write([]byte("first day\n")); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(2 * time.Minute)
+	if _, err := rf.Write([]byte("second day\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	rotated := filepath.Join(dir, "daily-2024-05-02T00-01-00.000.log")
+	bt, err := os.ReadFile(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bt) != "first day\n" {
+		t.Errorf("rotated file: have %q", string(bt))
+	}
+	bt, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bt) != "second day\n" {
+		t.Errorf("active file: have %q", string(bt))
+	}
+}
+
+func TestRotatingFileKeepsSiblings(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	siblings := []string{"app-access.log", "app-2.log", "app-2024-05-01.log.gz", "app-2024-05-01T00-00-00.000.x.log"}
+	for _, name := range siblings {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("keep"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rf, err := NewRotatingFile(path, RotationKeep(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	rf.now = func() time.Time { return clock }
+	for i := 0; i < 3; i++ {
+		if err := rf.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range siblings {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("sibling file %v is removed: %v", name, err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated := len(entries) - len(siblings) - 1; rotated != 1 {
+		t.Errorf("expected 1 rotated file kept, have %v", rotated)
+	}
+}
+
+func TestRotatingFileFailures(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rf, err := NewRotatingFile(path, RotationTimeFormat("2006/01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rf.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := rf.Rotate(); err == nil {
+		t.Errorf("rotation into missing directory succeeded")
+	}
+	if _, err := rf.Write([]byte("after\n")); err != nil {
+		t.Errorf("file is not reopened after failed rotation: %v", err)
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if bt, _ := os.ReadFile(path); string(bt) != "before\nafter\n" {
+		t.Errorf("active file: have %q", string(bt))
+	}
+
+	rf, err = NewRotatingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := false
+	rf.openFile = func(name string, flag int, perm os.FileMode) (*os.File, error) {
+		if !failed {
+			failed = true
+			return nil, errors.New("no descriptors")
+		}
+		return os.OpenFile(name, flag, perm)
+	}
+	if err := rf.Rotate(); err == nil {
+		t.Errorf("rotation with failed open succeeded")
+	}
+	if _, err := rf.Write([]byte("after\n")); err != nil {
+		t.Errorf("file is not reopened after failed open: %v", err)
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if bt, _ := os.ReadFile(path); string(bt) != "before\nafter\nafter\n" {
+		t.Errorf("active file after failed open: have %q", string(bt))
+	}
+
+	rf, err = NewRotatingFile(path, RotationCompress(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	rf.now = func() time.Time { return clock }
+	rotated := filepath.Join(dir, "app-2024-05-01T00-00-00.000.log")
+	rf.backgroundMu.Lock()
+	if err := rf.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(rotated+".gz", 0755); err != nil {
+		t.Fatal(err)
+	}
+	rf.backgroundMu.Unlock()
+	if err := rf.Close(); err == nil || !strings.Contains(err.Error(), "failed to compress") {
+		t.Errorf("compression error is not reported: %v", err)
+	}
+	if bt, _ := os.ReadFile(rotated); string(bt) != "before\nafter\nafter\n" {
+		t.Errorf("source of failed compression: have %q", string(bt))
+	}
+}
@@ -0,0 +1,305 @@
+package logman
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	keySuppressed = "suppressed"
+	keyTemplate   = "template"
+
+	// samplerMaxTemplates limits number of templates tracked by sampler.
+	// Messages of templates above limit share one counter.
+	samplerMaxTemplates = 4096
+)
+
+// samplingSettings describes sampler of level.
+type samplingSettings struct {
+	first      int
+	interval   time.Duration
+	thereafter int
+	rate       float64
+	burst      int
+	summary    time.Duration
+}
+
+// SamplingOption - settings of sampler.
+type SamplingOption func(*samplingSettings)
+
+// SampleFirst passes first n messages of each template per interval.
+// Following messages of interval are passed according to SampleThereafter.
+// Interval 0 (or negative) never restarts: first n messages of template are passed once.
+func SampleFirst(n int, interval time.Duration) SamplingOption {
+	if interval < 0 {
+		interval = 0
+	}
+	return func(ss *samplingSettings) {
+		ss.first = n
+		ss.interval = interval
+	}
+}
+
+// SampleThereafter passes every m-th message of template after first ones
+// (see SampleFirst). If m is 0 all of them are suppressed.
+func SampleThereafter(m int) SamplingOption {
+	return func(ss *samplingSettings) {
+		ss.thereafter = m
+	}
+}
+
+// SampleRate limits messages of each template with token bucket: perSecond
+// messages on average with bursts up to burst messages. Burst below 1 is set to 1,
+// otherwise bucket would never pass a message.
+func SampleRate(perSecond float64, burst int) SamplingOption {
+	if burst < 1 {
+		burst = 1
+	}
+	return func(ss *samplingSettings) {
+		ss.rate = perSecond
+		ss.burst = burst
+	}
+}
+
+// SampleSummary makes sampler report every period how many messages of each
+// template were suppressed. Summary is processed on the same level with fields
+// "suppressed" and "template" and is never sampled itself.
+func SampleSummary(every time.Duration) SamplingOption {
+	return func(ss *samplingSettings) {
+		ss.summary = every
+	}
+}
+
+// WithSampling - sets sampler of level (of all levels if level is ALL). Messages are
+// counted per template (format string of message). Levels that exit program are never sampled.
+func WithSampling(level string, opts ...SamplingOption) LogmanOptions {
+	settings := samplingSettings{}
+	for _, set := range opts {
+		set(&settings)
+	}
+	return func(o *options) {
+		if o.sampling == nil {
+			o.sampling = make(map[string]samplingSettings)
+		}
+		o.sampling[level] = settings
+	}
+}
+
+// samplers creates samplers of levels. Sampler for ALL is used for levels without own one.
+func (opt *options) samplers(levels levelTable) (map[string]*sampler, error) {
+	if len(opt.sampling) == 0 {
+		return nil, nil
+	}
+	samplers := make(map[string]*sampler)
+	for level, settings := range opt.sampling {
+		if level == ALL {
+			continue
+		}
+		lvl, ok := levels[level]
+		if !ok {
+			return nil, fmt.Errorf("logman has no level '%v'", level)
+		}
+		if lvl.osExit {
+			continue
+		}
+		samplers[level] = newSampler(level, settings)
+	}
+	if settings, ok := opt.sampling[ALL]; ok {
+		for name, lvl := range levels {
+			if _, ok := samplers[name]; ok || lvl.osExit {
+				continue
+			}
+			samplers[name] = newSampler(name, settings)
+		}
+	}
+	return samplers, nil
+}
+
+// sampler decides which messages of level are processed.
+type sampler struct {
+	level    string
+	settings samplingSettings
+	now      func() time.Time
+
+	mu        sync.Mutex
+	templates map[string]*sampledTemplate
+	stop      chan struct{}
+	stopped   sync.WaitGroup
+	stopOnce  sync.Once
+}
+
+type sampledTemplate struct {
+	windowStart time.Time
+	count       int
+	tokens      float64
+	refilled    time.Time
+	suppressed  uint64
+}
+
+func newSampler(level string, settings samplingSettings) *sampler {
+	return &sampler{
+		level:     level,
+		settings:  settings,
+		now:       time.Now,
+		templates: make(map[string]*sampledTemplate),
+		stop:      make(chan struct{}),
+	}
+}
+
+// messageTemplate returns format string of message or its text.
+func messageTemplate(msg Message) string {
+	if m, ok := msg.(*message); ok {
+		return m.format
+	}
+	if format, ok := msg.InputArgs()[-1].(string); ok {
+		return format
+	}
+	return fmt.Sprint(msg.Value(keyMessage))
+}
+
+// allow reports whether message should be processed. Summaries are always allowed.
+func (s *sampler) allow(msg Message) bool {
+	if m, ok := msg.(*message); ok && m.summary {
+		return true
+	}
+	template := messageTemplate(msg)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	st, ok := s.templates[template]
+	if !ok {
+		if len(s.templates) >= samplerMaxTemplates {
+			template = ""
+			st = s.templates[template]
+		}
+		if st == nil {
+			st = &sampledTemplate{windowStart: now, tokens: float64(s.settings.burst), refilled: now}
+			s.templates[template] = st
+		}
+	}
+	if s.settings.first > 0 {
+		if s.settings.interval > 0 && now.Sub(st.windowStart) >= s.settings.interval {
+			st.windowStart = now
+			st.count = 0
+		}
+		st.count++
+		if st.count > s.settings.first {
+			if s.settings.thereafter <= 0 || (st.count-s.settings.first)%s.settings.thereafter != 0 {
+				st.suppressed++
+				return false
+			}
+		}
+	}
+	if s.settings.rate > 0 {
+		st.tokens += now.Sub(st.refilled).Seconds() * s.settings.rate
+		if st.tokens > float64(s.settings.burst) {
+			st.tokens = float64(s.settings.burst)
+		}
+		st.refilled = now
+		if st.tokens < 1 {
+			st.suppressed++
+			return false
+		}
+		st.tokens--
+	}
+	return true
+}
+
+// takeSuppressed returns and resets counters of suppressed messages by template.
+func (s *sampler) takeSuppressed() map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	suppressed := make(map[string]uint64)
+	for template, st := range s.templates {
+		if st.suppressed > 0 {
+			suppressed[template] = st.suppressed
+			st.suppressed = 0
+		}
+	}
+	return suppressed
+}
+
+// start runs periodic summaries of sampler on l.
+func (s *sampler) start(l *Logger) {
+	if s.settings.summary <= 0 {
+		return
+	}
+	s.stopped.Add(1)
+	go func() {
+		defer s.stopped.Done()
+		ticker := time.NewTicker(s.settings.summary)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				emitSummaries(l, s.summaries())
+			}
+		}
+	}()
+}
+
+// halt stops periodic summaries and returns summaries of suppressed messages left.
+func (s *sampler) halt() []sampledSummary {
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.stopped.Wait()
+	if s.settings.summary <= 0 {
+		return nil
+	}
+	return s.summaries()
+}
+
+// sampledSummary is summary message of sampler with level it is processed on.
+type sampledSummary struct {
+	level string
+	msg   *message
+}
+
+// summaries takes counters of suppressed messages and returns summary of every template.
+func (s *sampler) summaries() []sampledSummary {
+	suppressed := s.takeSuppressed()
+	templates := make([]string, 0, len(suppressed))
+	for template := range suppressed {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	summaries := make([]sampledSummary, 0, len(templates))
+	for _, template := range templates {
+		msg := NewMessage("suppressed %v messages like %q", suppressed[template], template)
+		msg.SetField(keySuppressed, suppressed[template])
+		msg.SetField(keyTemplate, template)
+		msg.summary = true
+		summaries = append(summaries, sampledSummary{level: s.level, msg: msg})
+	}
+	return summaries
+}
+
+// emitSummaries processes summaries of samplers. It must not be called
+// with l.mu held: hooks and writers may reconfigure l.
+func emitSummaries(l *Logger, summaries []sampledSummary) {
+	for _, summary := range summaries {
+		if err := l.process(0, summary.msg, summary.level); err != nil {
+			fmt.Fprintf(os.Stderr, "logman: %v\n", err)
+		}
+	}
+}
+
+// startSamplers runs summaries of samplers of snapshot.
+func startSamplers(l *Logger, samplers map[string]*sampler) {
+	for _, s := range samplers {
+		s.start(l)
+	}
+}
+
+// haltSamplers stops summaries of samplers of snapshot and returns summaries left.
+func haltSamplers(samplers map[string]*sampler) []sampledSummary {
+	summaries := []sampledSummary{}
+	for _, s := range samplers {
+		summaries = append(summaries, s.halt()...)
+	}
+	return summaries
+}
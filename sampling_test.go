@@ -0,0 +1,168 @@
+package logman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampling(t *testing.T) {
+	buf := &lockedBuffer{}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(WARN, LevelImportance(ImportanceWARN))),
+		WithWriterNamed("buffer", buf, formatter),
+		WithSampling(INFO, SampleFirst(2, time.Hour), SampleThereafter(3), SampleSummary(time.Hour)),
+		WithSampling(WARN, SampleRate(1, 2)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	lgr.state.Load().samplers[WARN].now = func() time.Time { return now }
+
+	for i := 1; i <= 10; i++ {
+		lgr.Info("failed %v", i)
+	}
+	lgr.Info("other")
+	for i := 1; i <= 5; i++ {
+		lgr.Warn("limited %v", i)
+	}
+	now = now.Add(time.Second)
+	lgr.Warn("limited %v", 6)
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"failed 1", "failed 2", "failed 5", "failed 8", "other",
+		"limited 1", "limited 2", "limited 6",
+		`suppressed 6 messages like "failed %v"`,
+	}
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(buf.String(), " \n", "\n")), "\n")
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, have %q", expected, lines)
+	}
+}
+
+func TestSamplingConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`
+levels:
+  - name: error
+    importance: error
+    sampling: {first: 10, interval: 1s, thereafter: 100, rate: 0.5, burst: 3, summary: 1m}
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := cfg.Levels[0].Sampling
+	if sc == nil || sc.First != 10 || sc.Interval != time.Second || sc.Rate != 0.5 || sc.Summary != time.Minute {
+		t.Fatalf("unexpected sampling config %+v", sc)
+	}
+	opts, err := cfg.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgr, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lgr.Close(context.Background())
+	if lgr.state.Load().samplers[ERROR] == nil {
+		t.Errorf("sampler of level %v is not created", ERROR)
+	}
+}
+
+func TestErrorfSampledAsTemplate(t *testing.T) {
+	buf := &lockedBuffer{}
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(ERROR, LevelImportance(ImportanceERROR))),
+		WithWriterNamed("buffer", buf, NewFormatter(WithRequestedFields(Request_MessageOnly))),
+		WithSampling(ERROR, SampleFirst(2, time.Hour)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := lgr.Errorf("x %d", i); err.Error() != fmt.Sprintf("x %d", i) {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); out != "x 0 \nx 1 \n" {
+		t.Errorf("expected two messages of one template, have %q", out)
+	}
+	if templates := len(lgr.state.Load().samplers[ERROR].templates); templates != 1 {
+		t.Errorf("expected 1 template, have %v", templates)
+	}
+}
+
+func TestSamplingEdgeCases(t *testing.T) {
+	buf := &lockedBuffer{}
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(WARN, LevelImportance(ImportanceWARN))),
+		WithWriterNamed("buffer", buf, NewFormatter(WithRequestedFields(Request_MessageOnly))),
+		WithSampling(INFO, SampleFirst(2, 0)),
+		WithSampling(WARN, SampleRate(1, 0)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	lgr.state.Load().samplers[WARN].now = func() time.Time { return now }
+	for i := 1; i <= 5; i++ {
+		lgr.Info("first %v", i)
+		lgr.Warn("rate %v", i)
+	}
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); out != "first 1 \nrate 1 \nfirst 2 \n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestSamplingSummaryMarker(t *testing.T) {
+	buf := &lockedBuffer{}
+	reconfigured := make(chan struct{}, 10)
+	var lgr *Logger
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO)),
+		WithWriterNamed("buffer", buf, NewFormatter(WithRequestedFields(Request_MessageOnly))),
+		WithSampling(INFO, SampleFirst(1, time.Hour), SampleSummary(time.Hour)),
+		WithHooks(HookFunc(func(_ string, msg Message) Message {
+			if msg.Value(keyTemplate) != nil {
+				lgr.SetMinimumImportance(ImportanceINFO)
+				reconfigured <- struct{}{}
+			}
+			return msg
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		lgr.With(Any(keySuppressed, i)).Info("user field")
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- lgr.Reload(WithLogLevels(NewLoggingLevel(INFO)), WithWriterNamed("buffer", buf, NewFormatter(WithRequestedFields(Request_MessageOnly))))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("hook reconfiguring logger while summary is emitted by Reload deadlocks")
+	}
+	if len(reconfigured) != 1 {
+		t.Errorf("summary is not emitted by Reload")
+	}
+	if text := buf.String(); text != "user field \nsuppressed 2 messages like \"user field\" \n" {
+		t.Errorf("message with field %q is not sampled: %q", keySuppressed, text)
+	}
+}
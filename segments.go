@@ -0,0 +1,266 @@
+package logman
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSegmentMaxSize = 16 << 20
+	defaultSegmentMaxAge  = time.Hour
+	segmentIndexFile      = "segments.idx"
+)
+
+// segmentSettings describes how directory writers store messages.
+type segmentSettings struct {
+	perMessage bool
+	maxSize    int64
+	maxAge     time.Duration
+}
+
+func defaultSegmentSettings() segmentSettings {
+	return segmentSettings{
+		maxSize: defaultSegmentMaxSize,
+		maxAge:  defaultSegmentMaxAge,
+	}
+}
+
+// SegmentOption - settings for directory writers.
+type SegmentOption func(*segmentSettings)
+
+// SegmentMaxSize sets size in bytes after which new segment is started. 0 disables size limit.
+func SegmentMaxSize(bytes int64) SegmentOption {
+	return func(s *segmentSettings) {
+		s.maxSize = bytes
+	}
+}
+
+// SegmentMaxAge sets age after which new segment is started. 0 disables age limit.
+// Age is checked when message is written: idle segment is rolled and indexed
+// by next message of its app and level or by Close.
+func SegmentMaxAge(age time.Duration) SegmentOption {
+	return func(s *segmentSettings) {
+		s.maxAge = age
+	}
+}
+
+// SegmentPerMessage restores legacy mode: every message is written to its own file.
+func SegmentPerMessage() SegmentOption {
+	return func(s *segmentSettings) {
+		s.perMessage = true
+	}
+}
+
+// WithDirectorySegments - sets how directory writers store messages.
+// By default messages are appended to segment file '<unixnano>_<app>_<level>.lmm'
+// (one per app and level) which is rolled by size or age. Closed segments
+// are listed in 'segments.idx' file of directory as JSON lines.
+func WithDirectorySegments(opts ...SegmentOption) LogmanOptions {
+	return func(o *options) {
+		for _, set := range opts {
+			set(&o.segments)
+		}
+	}
+}
+
+// segmentIndexEntry describes closed segment in index file.
+type segmentIndexEntry struct {
+	Segment  string `json:"segment"`
+	App      string `json:"app"`
+	Level    string `json:"level"`
+	First    string `json:"first"`
+	Last     string `json:"last"`
+	Messages int    `json:"messages"`
+	Bytes    int64  `json:"bytes"`
+}
+
+type segment struct {
+	name     string
+	file     *os.File
+	app      string
+	level    string
+	opened   time.Time
+	first    time.Time
+	last     time.Time
+	messages int
+	size     int64
+}
+
+// dirDestination writes messages to segment files in directory.
+type dirDestination struct {
+	path     string
+	settings segmentSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	segments map[string]*segment
+}
+
+func newDirDestination(path string, settings segmentSettings) *dirDestination {
+	return &dirDestination{
+		path:     path,
+		settings: settings,
+		now:      time.Now,
+		segments: make(map[string]*segment),
+	}
+}
+
+func (d *dirDestination) filePath(name string) string {
+	sep := string(filepath.Separator)
+	return strings.TrimSuffix(d.path, sep) + sep + name
+}
+
+func messageTime(message Message) time.Time {
+	if tm, ok := createdAt(message); ok {
+		return tm
+	}
+	msgTime, err := time.Parse(time.RFC3339Nano, fmt.Sprintf("%v", message.Value(keyTime)))
+	if err != nil {
+		return time.Now()
+	}
+	return msgTime
+}
+
+func (d *dirDestination) write(app, level string, message Message, text []byte) error {
+	msgTime := messageTime(message)
+	if d.settings.perMessage {
+		return d.writeSingle(app, level, msgTime, text)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := app + "_" + level
+	seg := d.segments[key]
+	errorStack := []error{}
+	if seg != nil && d.mustRoll(seg, len(text)) {
+		// segment file is closed even if closing reports error, so it is never used again
+		if err := d.closeSegment(seg); err != nil {
+			errorStack = append(errorStack, err)
+		}
+		delete(d.segments, key)
+		seg = nil
+	}
+	if seg == nil {
+		opened, err := d.openSegment(app, level, msgTime)
+		if err != nil {
+			return joinErrors("writing to directory failed", append(errorStack, err)...)
+		}
+		seg = opened
+		d.segments[key] = seg
+	}
+	n, err := seg.file.Write(text)
+	seg.size += int64(n)
+	if err != nil {
+		return joinErrors("writing to directory failed", append(errorStack, err)...)
+	}
+	if seg.messages == 0 {
+		seg.first = msgTime
+	}
+	seg.last = msgTime
+	seg.messages++
+	return joinErrors("writing to directory failed", errorStack...)
+}
+
+func (d *dirDestination) writeSingle(app, level string, msgTime time.Time, text []byte) error {
+	msgFile := d.filePath(fmt.Sprintf("%v_%v_%v.lmm", msgTime.UnixNano(), app, level))
+	f, err := os.OpenFile(msgFile, flags, perm)
+	if err != nil {
+		return fmt.Errorf("failed to open writer '%v': %v", d.path, err)
+	}
+	if _, err := f.Write(text); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (d *dirDestination) mustRoll(seg *segment, incoming int) bool {
+	if d.settings.maxSize > 0 && seg.size > 0 && seg.size+int64(incoming) > d.settings.maxSize {
+		return true
+	}
+	if d.settings.maxAge > 0 && d.now().Sub(seg.opened) >= d.settings.maxAge {
+		return true
+	}
+	return false
+}
+
+func (d *dirDestination) openSegment(app, level string, msgTime time.Time) (*segment, error) {
+	name := fmt.Sprintf("%v_%v_%v.lmm", msgTime.UnixNano(), app, level)
+	f, err := os.OpenFile(d.filePath(name), flags, perm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open segment '%v': %v", name, err)
+	}
+	return &segment{
+		name:   name,
+		file:   f,
+		app:    app,
+		level:  level,
+		opened: d.now(),
+	}, nil
+}
+
+// closeSegment closes segment file and appends its description to index.
+func (d *dirDestination) closeSegment(seg *segment) error {
+	errorStack := []error{}
+	if err := seg.file.Close(); err != nil {
+		errorStack = append(errorStack, fmt.Errorf("failed to close segment '%v': %v", seg.name, err))
+	}
+	entry := segmentIndexEntry{
+		Segment:  seg.name,
+		App:      seg.app,
+		Level:    seg.level,
+		First:    seg.first.Format(time.RFC3339Nano),
+		Last:     seg.last.Format(time.RFC3339Nano),
+		Messages: seg.messages,
+		Bytes:    seg.size,
+	}
+	if err := d.appendIndex(entry); err != nil {
+		errorStack = append(errorStack, err)
+	}
+	return joinErrors("segment closing failed", errorStack...)
+}
+
+func (d *dirDestination) appendIndex(entry segmentIndexEntry) error {
+	bt, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(d.filePath(segmentIndexFile), flags, perm)
+	if err != nil {
+		return fmt.Errorf("failed to open segment index: %v", err)
+	}
+	if _, err := f.Write(append(bt, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write segment index: %v", err)
+	}
+	return f.Close()
+}
+
+func (d *dirDestination) sync() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	errorStack := []error{}
+	for _, seg := range d.segments {
+		if err := seg.file.Sync(); err != nil {
+			errorStack = append(errorStack, err)
+		}
+	}
+	return joinErrors("segment sync failed", errorStack...)
+}
+
+func (d *dirDestination) close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	errorStack := []error{}
+	for key, seg := range d.segments {
+		if err := d.closeSegment(seg); err != nil {
+			errorStack = append(errorStack, err)
+		}
+		delete(d.segments, key)
+	}
+	return joinErrors("closing segments failed", errorStack...)
+}
@@ -0,0 +1,105 @@
+package logman
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectorySegments(t *testing.T) {
+	dir := t.TempDir() + string(filepath.Separator)
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithAppName("segtest"),
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(WARN, LevelImportance(ImportanceWARN))),
+		WithDirectorySegments(SegmentMaxSize(200)),
+		WithGlobalWriterFormatter(dir, formatter),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 40; i++ {
+		lgr.Info("info message %03d", i)
+	}
+	lgr.Warn("single warning")
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments := 0
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".lmm") {
+			segments++
+		}
+	}
+	f, err := os.Open(filepath.Join(dir, segmentIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	indexed := 0
+	messages := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry := segmentIndexEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.App != "segtest" || entry.Bytes > 200 {
+			t.Errorf("bad index entry: %+v", entry)
+		}
+		messages[entry.Level] += entry.Messages
+		indexed++
+	}
+	if segments < 5 || segments > 10 {
+		t.Errorf("expected messages to be batched in few segments, have %v files", segments)
+	}
+	if indexed != segments {
+		t.Errorf("index lists %v segments, directory has %v", indexed, segments)
+	}
+	if messages[INFO] != 40 || messages[WARN] != 1 {
+		t.Errorf("index message counts: %v", messages)
+	}
+}
+
+func TestDirectorySegmentIndexFailure(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, segmentIndexFile), 0777); err != nil {
+		t.Fatal(err)
+	}
+	d := newDirDestination(dir, segmentSettings{maxSize: 1})
+	for i, text := range []string{"first\n", "second\n", "third\n"} {
+		err := d.write("app", INFO, NewMessage(text), []byte(text))
+		if i > 0 && (err == nil || strings.Contains(err.Error(), "file already closed")) {
+			t.Errorf("write %v: expected index error only, have %v", i, err)
+		}
+	}
+	if err := d.close(); err == nil {
+		t.Errorf("index error is not reported by Close")
+	}
+	written := []string{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".lmm") {
+			content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			written = append(written, string(content))
+		}
+	}
+	if strings.Join(written, "") != "first\nsecond\nthird\n" {
+		t.Errorf("messages are lost after index failure: %q", written)
+	}
+}
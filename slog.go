@@ -0,0 +1,282 @@
+package logman
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// SlogImportance maps slog.Level onto logman importance.
+// Standard slog levels become ImportanceDEBUG, ImportanceINFO, ImportanceWARN and ImportanceERROR,
+// levels below slog.LevelDebug become ImportanceTRACE. Levels between standard
+// ones are interpolated, so slog.LevelInfo+2 is more important than slog.LevelInfo.
+func SlogImportance(level slog.Level) int {
+	steps := []struct {
+		level      slog.Level
+		importance int
+	}{
+		{slog.LevelDebug - 4, ImportanceTRACE},
+		{slog.LevelDebug, ImportanceDEBUG},
+		{slog.LevelInfo, ImportanceINFO},
+		{slog.LevelWarn, ImportanceWARN},
+		{slog.LevelError, ImportanceERROR},
+	}
+	if level <= steps[0].level {
+		return steps[0].importance
+	}
+	for i := 1; i < len(steps); i++ {
+		low, high := steps[i-1], steps[i]
+		if level > high.level {
+			continue
+		}
+		span := int(high.level - low.level)
+		return low.importance + (high.importance-low.importance)*int(level-low.level)/span
+	}
+	importance := ImportanceERROR + int(level-slog.LevelError)
+	if importance >= ImportanceFATAL {
+		importance = ImportanceFATAL - 1
+	}
+	return importance
+}
+
+// ImportanceSlogLevel maps logman importance onto slog.Level.
+func ImportanceSlogLevel(importance int) slog.Level {
+	switch {
+	case importance >= ImportanceFATAL:
+		return slog.LevelError + 4
+	case importance >= ImportanceERROR:
+		return slog.LevelError
+	case importance >= ImportanceWARN:
+		return slog.LevelWarn
+	case importance >= ImportanceINFO:
+		return slog.LevelInfo
+	case importance >= ImportanceDEBUG:
+		return slog.LevelDebug
+	}
+	return slog.LevelDebug - 4
+}
+
+// levelByImportance returns most important level which importance does not exceed importance provided.
+// Levels that exit program are never chosen.
+func (l *Logger) levelByImportance(importance int) *loggingLevel {
+	var found *loggingLevel
+	for _, lvl := range l.levels() {
+		if lvl.osExit || lvl.importance > importance {
+			continue
+		}
+		switch {
+		case found == nil, lvl.importance > found.importance:
+			found = lvl
+		case lvl.importance == found.importance && lvl.name < found.name:
+			found = lvl
+		}
+	}
+	return found
+}
+
+// SlogHandler is slog.Handler writing records through logman levels, writers and formatters.
+// Record level is mapped by SlogImportance and written on most important Logger level
+// not exceeding it. Attributes become Message fields, groups qualify keys with "group.".
+type SlogHandler struct {
+	logger *Logger
+	attrs  []messageField
+	groups []string
+}
+
+// NewSlogHandler returns slog.Handler backed by l. If l is nil default Logger is used.
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+func (h *SlogHandler) lgr() *Logger {
+	if h.logger == nil {
+		return Default()
+	}
+	return h.logger.active()
+}
+
+// Enabled reports whether Logger processes records of level provided on level they are written to
+// (see Logger.Enabled): verbosity rules and minimum importance override of ctx
+// (see ContextWithImportance) are respected.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	l := h.lgr()
+	lvl := l.levelByImportance(SlogImportance(level))
+	return lvl != nil && l.enabled(ctx, l.slogCallDepth(), lvl.name)
+}
+
+// Handle converts record into Message and processes it with fields of ctx.
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	l := h.lgr()
+	lvl := l.levelByImportance(SlogImportance(record.Level))
+	if lvl == nil {
+		return nil
+	}
+	msg := NewMessage(strings.ReplaceAll(record.Message, "%", "%%"))
+	if !record.Time.IsZero() {
+		msg.SetField(keyTime, record.Time)
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		msg.SetField(keyFile, frame.File)
+		msg.SetField(keyLine, frame.Line)
+		msg.SetField(keyFunc, frame.Function)
+	}
+	msg.WithFields(h.attrs...)
+	prefix := groupPrefix(h.groups)
+	record.Attrs(func(attr slog.Attr) bool {
+		msg.WithFields(slogFields(prefix, attr)...)
+		return true
+	})
+	return l.processCtx(ctx, l.slogCallDepth(), msg, lvl.name)
+}
+
+// slogCallDepth returns depth of call site of slog for handler method calling it:
+// frames of log/slog between handler and user code are skipped. Call site matters
+// only for verbosity rules of packages, so stack is not walked without them.
+func (l *Logger) slogCallDepth() int {
+	if rules := l.state.Load().rules; rules == nil || len(rules.packages) == 0 {
+		return 1
+	}
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	depth := 1
+	for {
+		frame, more := frames.Next()
+		if !more || callerPackage(frame.Function) != "log/slog" {
+			return depth
+		}
+		depth++
+	}
+}
+
+// WithAttrs returns handler adding attrs to every record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	prefix := groupPrefix(h.groups)
+	fields := append([]messageField{}, h.attrs...)
+	for _, attr := range attrs {
+		fields = append(fields, slogFields(prefix, attr)...)
+	}
+	return &SlogHandler{logger: h.logger, attrs: fields, groups: h.groups}
+}
+
+// WithGroup returns handler qualifying keys of following attributes with name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := append(append([]string{}, h.groups...), name)
+	return &SlogHandler{logger: h.logger, attrs: h.attrs, groups: groups}
+}
+
+func groupPrefix(groups []string) string {
+	if len(groups) == 0 {
+		return ""
+	}
+	return strings.Join(groups, ".") + "."
+}
+
+// slogFields flattens attribute into fields. Empty attributes are ignored,
+// groups with empty key are inlined.
+func slogFields(prefix string, attr slog.Attr) []messageField {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return nil
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		return []messageField{{prefix + attr.Key, attr.Value.Any()}}
+	}
+	groupAttrs := attr.Value.Group()
+	if len(groupAttrs) == 0 {
+		return nil
+	}
+	if attr.Key != "" {
+		prefix += attr.Key + "."
+	}
+	fields := []messageField{}
+	for _, ga := range groupAttrs {
+		fields = append(fields, slogFields(prefix, ga)...)
+	}
+	return fields
+}
+
+// slogDestination forwards messages to slog.Handler.
+type slogDestination struct {
+	handler slog.Handler
+}
+
+// WithSlogHandler - registers slog.Handler as writer named name and attaches it to levels
+// provided (to all levels if none). Messages written to it are converted to slog.Record:
+// level importance is mapped by ImportanceSlogLevel, message text is rendered without colors
+// and other fields become attributes. Formatter of this writer is ignored, so it can be
+// attached to other levels with WithWriter(name, nil).
+func WithSlogHandler(name string, h slog.Handler, levels ...string) LogmanOptions {
+	return func(o *options) {
+		o.namedWriters = append(o.namedWriters, namedWriter{
+			name: name,
+			open: func() (destination, error) {
+				return &slogDestination{handler: h}, nil
+			},
+		})
+		if len(levels) == 0 {
+			o.globalWriterKeys = append(o.globalWriterKeys, name)
+			o.globalFormatters = append(o.globalFormatters, nil)
+			o.globalFilters = append(o.globalFilters, nil)
+			return
+		}
+		for _, level := range levels {
+			o.levelWriters = append(o.levelWriters, levelWriter{level: level, writerKey: name})
+		}
+	}
+}
+
+func (s *slogDestination) write(_, level string, message Message, _ []byte) error {
+	importance := ImportanceINFO
+	if l := loggerOf(message); l != nil {
+		if lvl, ok := l.levels()[level]; ok {
+			importance = lvl.importance
+		}
+	}
+	slogLevel := ImportanceSlogLevel(importance)
+	ctx := contextOf(message)
+	if !s.handler.Enabled(ctx, slogLevel) {
+		return nil
+	}
+	text, err := stdFormatMessage(message, nil)
+	if err != nil {
+		text = ""
+	}
+	record := slog.NewRecord(messageTime(message), slogLevel, text, 0)
+	for _, key := range message.Fields() {
+		switch key {
+		case keyTime, keyLevel, keyMessage:
+			continue
+		}
+		record.AddAttrs(slogAttr(key, message.Value(key)))
+	}
+	return s.handler.Handle(ctx, record)
+}
+
+// slogAttr converts field to slog.Attr keeping objects as groups.
+func slogAttr(key string, value interface{}) slog.Attr {
+	obj, ok := value.(ObjectValue)
+	if !ok {
+		return slog.Any(key, value)
+	}
+	attrs := make([]any, 0, len(obj))
+	for _, fld := range obj {
+		attrs = append(attrs, slogAttr(fld.key, fld.value))
+	}
+	return slog.Group(key, attrs...)
+}
+
+func (s *slogDestination) sync() error {
+	return nil
+}
+
+func (s *slogDestination) close() error {
+	return nil
+}
@@ -0,0 +1,102 @@
+package logman
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewFormatter(WithRequestedFields([]string{keyLevel, keyMessage, "req", "g.k"}))
+	lgr, err := New(WithWriterNamed("buffer", buf, formatter))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sl := slog.New(NewSlogHandler(lgr)).With("req", 1).WithGroup("g")
+	sl.Info("hello 100%", "k", 2)
+	sl.Warn("warned", slog.Group("", "k", 3))
+	sl.Log(context.Background(), slog.LevelError+20, "not fatal", "k", 4)
+	expected := "[info] hello 100% req=1 g.k=2 \n" +
+		"[warn] warned req=1 g.k=3 \n" +
+		"[error] not fatal req=1 g.k=4 \n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%q\nhave:\n%q", expected, buf.String())
+	}
+	if NewSlogHandler(lgr).Enabled(context.Background(), slog.LevelDebug-8) != true {
+		t.Errorf("trace level must be enabled for ImportanceALL")
+	}
+}
+
+func TestForwardToSlogHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(WARN, LevelImportance(ImportanceWARN))),
+		WithSlogHandler("slog", handler, WARN),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgr.Info("skipped")
+	lgr.Warn("disk %v%% full", 93)
+	if strings.TrimSpace(buf.String()) != `level=WARN msg="disk 93% full"` {
+		t.Errorf("have %q", buf.String())
+	}
+}
+
+type ctxKey struct{}
+
+type ctxRecorder struct {
+	slog.Handler
+	values []interface{}
+}
+
+func (r *ctxRecorder) Handle(ctx context.Context, record slog.Record) error {
+	r.values = append(r.values, ctx.Value(ctxKey{}))
+	return nil
+}
+
+func TestSlogEnabledAndContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	recorder := &ctxRecorder{Handler: slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})}
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(DEBUG, LevelImportance(ImportanceDEBUG))),
+		WithAppLogLevelImportance(ImportanceINFO),
+		WithSlogHandler("slog", recorder),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewSlogHandler(lgr)
+	if handler.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("debug is enabled below minimum importance")
+	}
+	if !handler.Enabled(ContextWithImportance(context.Background(), ImportanceDEBUG), slog.LevelDebug) {
+		t.Errorf("importance override of context is ignored")
+	}
+	if err := lgr.SetVerbosity("logman=debug"); err != nil {
+		t.Fatal(err)
+	}
+	slog.New(handler).Debug("package debug")
+	if len(recorder.values) != 1 {
+		t.Errorf("verbosity rule of package is ignored")
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+	if err := lgr.InfoCtx(ctx, "with context"); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.values) != 2 || recorder.values[1] != "request-1" {
+		t.Errorf("context is not passed to slog handler: %v", recorder.values)
+	}
+}
@@ -0,0 +1,178 @@
+package logman
+
+import (
+	"log"
+	"runtime"
+	"strings"
+)
+
+// StdLogBridge is io.Writer turning every written line into Message processed on level.
+// It can be set as output of standard log package or passed to third-party libraries
+// exposing io.Writer based log hooks. Each Write is expected to contain whole lines.
+type StdLogBridge struct {
+	logger     *Logger
+	level      string
+	parseLevel bool
+	skip       int
+}
+
+// BridgeOption - settings for StdLogBridge.
+type BridgeOption func(*StdLogBridge)
+
+// BridgeParseLevel enables detection of level by line prefix like "[WARN]" or "warn:".
+// Prefix is matched against level names and tags (case insensitive) and removed from message.
+// Lines without known prefix are processed on default level of bridge.
+func BridgeParseLevel(parse bool) BridgeOption {
+	return func(b *StdLogBridge) {
+		b.parseLevel = parse
+	}
+}
+
+// BridgeCallerSkip sets number of additional stack frames to skip when caller is reported.
+// Use it when library wraps log calls into its own helper functions.
+func BridgeCallerSkip(skip int) BridgeOption {
+	return func(b *StdLogBridge) {
+		b.skip = skip
+	}
+}
+
+// NewStdLogBridge returns bridge writing to level of l. If l is nil default Logger is used.
+func NewStdLogBridge(l *Logger, level string, opts ...BridgeOption) *StdLogBridge {
+	b := StdLogBridge{logger: l, level: level}
+	for _, set := range opts {
+		set(&b)
+	}
+	return &b
+}
+
+func (b *StdLogBridge) lgr() *Logger {
+	if b.logger == nil {
+		return Default()
+	}
+	return b.logger
+}
+
+// Write processes every non-empty line of p as separate Message.
+func (b *StdLogBridge) Write(p []byte) (int, error) {
+	l := b.lgr()
+	errorStack := []error{}
+	file, line, fn := "", 0, ""
+	for _, text := range strings.Split(string(p), "\n") {
+		text = strings.TrimSuffix(text, "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		level := b.level
+		if b.parseLevel {
+			if found, rest, ok := l.levelPrefix(text); ok {
+				level, text = found, rest
+			}
+		}
+		msg := NewMessage(strings.ReplaceAll(text, "%", "%%"))
+		if lvl, ok := l.levels()[level]; ok && lvl.callerInfo {
+			if file == "" {
+				file, line, fn = bridgeCaller(b.skip)
+			}
+			msg.SetField(keyFile, file)
+			msg.SetField(keyLine, line)
+			msg.SetField(keyFunc, fn)
+		}
+		if err := l.process(1, msg, level); err != nil {
+			errorStack = append(errorStack, err)
+		}
+	}
+	if err := joinErrors("bridging log failed", errorStack...); err != nil {
+		return len(p), err
+	}
+	return len(p), nil
+}
+
+// bridgeCaller returns first stack frame outside of standard log package and this bridge.
+func bridgeCaller(skip int) (string, int, string) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isBridgeFrame(frame.Function) {
+			if skip == 0 {
+				return frame.File, frame.Line, frame.Function
+			}
+			skip--
+		}
+		if !more {
+			return "", 0, ""
+		}
+	}
+}
+
+func isBridgeFrame(function string) bool {
+	return strings.HasPrefix(function, "log.") || strings.Contains(function, "logman.(*StdLogBridge)")
+}
+
+var levelPrefixAliases = map[string]string{
+	"warning": WARN,
+	"err":     ERROR,
+	"crit":    FATAL,
+}
+
+// levelPrefix detects leading "[LEVEL]" or "LEVEL:" in text.
+// It returns level name and text without prefix.
+func (l *Logger) levelPrefix(text string) (string, string, bool) {
+	trimmed := strings.TrimLeft(text, " \t")
+	token, rest := "", ""
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		end := strings.Index(trimmed, "]")
+		if end < 0 {
+			return "", text, false
+		}
+		token, rest = trimmed[1:end], trimmed[end+1:]
+	default:
+		end := strings.Index(trimmed, ":")
+		if end < 0 || strings.ContainsAny(trimmed[:end], " \t") {
+			return "", text, false
+		}
+		token, rest = trimmed[:end], trimmed[end+1:]
+	}
+	token = strings.ToLower(strings.TrimSpace(token))
+	if alias, ok := levelPrefixAliases[token]; ok {
+		token = alias
+	}
+	for name, lvl := range l.levels() {
+		if strings.ToLower(name) == token || strings.ToLower(lvl.tag) == token {
+			return name, strings.TrimLeft(rest, " \t"), true
+		}
+	}
+	return "", text, false
+}
+
+// NewStdLogger returns *log.Logger which lines are processed on level of l.
+func (l *Logger) NewStdLogger(level string, opts ...BridgeOption) *log.Logger {
+	return log.New(NewStdLogBridge(l, level, opts...), "", 0)
+}
+
+// RedirectStdLog sets output of standard log package to level of l.
+// Flags and prefix of standard logger are cleared as time and caller are added by logman.
+// Returned function restores previous output, flags and prefix.
+func (l *Logger) RedirectStdLog(level string, opts ...BridgeOption) func() {
+	return redirectStdLog(NewStdLogBridge(l, level, opts...))
+}
+
+// RedirectStdLog sets output of standard log package to level of default Logger.
+// Returned function restores previous output, flags and prefix.
+func RedirectStdLog(level string, opts ...BridgeOption) func() {
+	return redirectStdLog(NewStdLogBridge(nil, level, opts...))
+}
+
+func redirectStdLog(bridge *StdLogBridge) func() {
+	output, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	log.SetOutput(bridge)
+	log.SetFlags(0)
+	log.SetPrefix("")
+	return func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}
+}
@@ -0,0 +1,39 @@
+package logman
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"runtime"
+	"testing"
+)
+
+func TestStdLogBridge(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewFormatter(WithRequestedFields([]string{keyLevel, keyMessage, keyCallerShort}))
+	lgr, err := New(
+		WithLogLevels(
+			NewLoggingLevel(INFO, LevelCallerInfo(true)),
+			NewLoggingLevel(WARN, LevelImportance(ImportanceWARN), LevelCallerInfo(true)),
+		),
+		WithWriterNamed("buffer", buf, formatter),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdLogger := lgr.NewStdLogger(INFO, BridgeParseLevel(true))
+	_, _, line, _ := runtime.Caller(0)
+	stdLogger.Printf("[WARN] disk at %v%%", 93)
+	stdLogger.Println("plain line")
+
+	restore := lgr.RedirectStdLog(INFO, BridgeParseLevel(true))
+	log.Print("warning: redirected")
+	restore()
+
+	expected := fmt.Sprintf("[warn] disk at 93%% \n  [caller=stdlog_test.go:%v] \n", line+1) +
+		fmt.Sprintf("[info] plain line \n  [caller=stdlog_test.go:%v] \n", line+2) +
+		fmt.Sprintf("[warn] redirected \n  [caller=stdlog_test.go:%v] \n", line+5)
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\nhave:\n%v", expected, buf.String())
+	}
+}
@@ -0,0 +1,192 @@
+package logman
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const keyComponent = "component"
+
+// verbosityRules override minimum importance for messages of matching caller package
+// or component. Rules are immutable, result for each call site is cached.
+type verbosityRules struct {
+	spec       string
+	packages   []verbosityRule
+	components []verbosityRule
+	cache      sync.Map // call site pc -> importance (-1 if no rule matches)
+}
+
+type verbosityRule struct {
+	pattern    string
+	importance int
+}
+
+// WithVerbosity - sets vmodule-style rules overriding minimum importance per caller
+// package or component (see SetVerbosity for spec format).
+func WithVerbosity(spec string) LogmanOptions {
+	return func(o *options) {
+		o.verbosity = spec
+	}
+}
+
+// SetVerbosity replaces verbosity rules of l. Spec is comma separated list of
+// pattern=importance where importance is level name or number:
+//
+//	github.com/acme/app/db/*=trace,net=warn,component:billing=debug
+//
+// Pattern with "component:" prefix matches value of message field "component".
+// Pattern containing "/" matches caller package path ("*" does not cross "/",
+// trailing "/..." matches package and all packages below). Other patterns match
+// last element of caller package path. Glob syntax is that of path.Match.
+// Component rules are checked before package rules, first matching rule wins;
+// messages matching no rule use minimum importance of Logger. Minimum importance
+// of context (see ContextWithImportance) takes precedence over rules.
+// Empty spec removes all rules.
+func (l *Logger) SetVerbosity(spec string) error {
+	rules, err := parseVerbosity(spec, l.levels().importances())
+	if err != nil {
+		return err
+	}
+	l.storeRules(rules)
+	return nil
+}
+
+// storeRules publishes snapshot with new verbosity rules.
+func (l *Logger) storeRules(rules *verbosityRules) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	next := *l.state.Load()
+	next.rules = rules
+	l.state.Store(&next)
+}
+
+// Verbosity returns verbosity rules spec of l.
+func (l *Logger) Verbosity() string {
+	if rules := l.state.Load().rules; rules != nil {
+		return rules.spec
+	}
+	return ""
+}
+
+// SetVerbosity replaces verbosity rules of default Logger.
+func SetVerbosity(spec string) error {
+	return Default().SetVerbosity(spec)
+}
+
+// parseVerbosity returns nil rules for empty spec. Importance may be name of known level.
+func parseVerbosity(spec string, known map[string]int) (*verbosityRules, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	rules := verbosityRules{spec: spec}
+	problems := []error{}
+	for _, item := range strings.Split(spec, ",") {
+		pattern, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			problems = append(problems, fmt.Errorf("rule '%v': expected pattern=importance", item))
+			continue
+		}
+		importance, err := parseImportance(value, known)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("rule '%v': %v", item, err))
+			continue
+		}
+		if component, ok := strings.CutPrefix(pattern, "component:"); ok {
+			if _, err := path.Match(component, ""); err != nil {
+				problems = append(problems, fmt.Errorf("rule '%v': bad pattern: %v", item, err))
+				continue
+			}
+			rules.components = append(rules.components, verbosityRule{pattern: component, importance: importance})
+			continue
+		}
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/..."), ""); err != nil {
+			problems = append(problems, fmt.Errorf("rule '%v': bad pattern: %v", item, err))
+			continue
+		}
+		rules.packages = append(rules.packages, verbosityRule{pattern: pattern, importance: importance})
+	}
+	if err := joinErrors("bad verbosity spec", problems...); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// minimum returns minimum importance for message of caller skip frames above.
+// Component rules are not checked if msg is nil.
+func (vr *verbosityRules) minimum(skip int, msg Message, fallback int) int {
+	if msg != nil && len(vr.components) > 0 {
+		if component := msg.Value(keyComponent); component != nil {
+			name := fmt.Sprint(component)
+			for _, rule := range vr.components {
+				if matched, _ := path.Match(rule.pattern, name); matched {
+					return rule.importance
+				}
+			}
+		}
+	}
+	if len(vr.packages) == 0 {
+		return fallback
+	}
+	pcs := [1]uintptr{}
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return fallback
+	}
+	if cached, ok := vr.cache.Load(pcs[0]); ok {
+		if importance := cached.(int); importance >= 0 {
+			return importance
+		}
+		return fallback
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	importance := vr.packageImportance(callerPackage(frame.Function))
+	vr.cache.Store(pcs[0], importance)
+	if importance >= 0 {
+		return importance
+	}
+	return fallback
+}
+
+// packageImportance returns importance of first rule matching package or -1.
+func (vr *verbosityRules) packageImportance(pkg string) int {
+	for _, rule := range vr.packages {
+		if matchPackage(rule.pattern, pkg) {
+			return rule.importance
+		}
+	}
+	return -1
+}
+
+func matchPackage(pattern, pkg string) bool {
+	if !strings.Contains(pattern, "/") {
+		pkg = path.Base(pkg)
+	}
+	if base, ok := strings.CutSuffix(pattern, "/..."); ok {
+		if matched, _ := path.Match(base, pkg); matched {
+			return true
+		}
+		for dir := path.Dir(pkg); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if matched, _ := path.Match(base, dir); matched {
+				return true
+			}
+		}
+		return false
+	}
+	matched, _ := path.Match(pattern, pkg)
+	return matched
+}
+
+// callerPackage extracts package path from function name like
+// "github.com/acme/app/db.(*Store).Query".
+func callerPackage(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return function
+	}
+	return function[:slash+1+dot]
+}
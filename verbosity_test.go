@@ -0,0 +1,67 @@
+package logman
+
+import (
+	"testing"
+)
+
+func TestVerbosityRules(t *testing.T) {
+	buf := &lockedBuffer{}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(
+			NewLoggingLevel(INFO),
+			NewLoggingLevel(DEBUG, LevelImportance(ImportanceDEBUG)),
+			NewLoggingLevel(TRACE, LevelImportance(ImportanceTRACE)),
+		),
+		WithWriterNamed("buffer", buf, formatter),
+		WithAppLogLevelImportance(ImportanceINFO),
+		WithVerbosity("logman=debug,component:billing=trace,component:auth=warn"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lgr.Debug(NewMessage("package debug"))
+	lgr.Trace(NewMessage("skipped"))
+	lgr.With(NewField(keyComponent, "billing")).Trace(NewMessage("billing trace"))
+	lgr.With(NewField(keyComponent, "auth")).Info("skipped")
+
+	if err := lgr.SetVerbosity("github.com/Galdoba/*=trace"); err != nil {
+		t.Fatal(err)
+	}
+	lgr.Trace(NewMessage("package trace"))
+	if err := lgr.SetVerbosity("other=trace"); err != nil {
+		t.Fatal(err)
+	}
+	lgr.Debug(NewMessage("skipped"))
+	if err := lgr.SetVerbosity("logman=nope,=debug"); err == nil {
+		t.Errorf("expected error for bad spec")
+	}
+	if lgr.Verbosity() != "other=trace" {
+		t.Errorf("bad spec must keep rules, have %q", lgr.Verbosity())
+	}
+	expected := "package debug \nbilling trace \npackage trace \n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, have %q", expected, buf.String())
+	}
+}
+
+func TestMatchPackage(t *testing.T) {
+	for _, tc := range []struct {
+		function string
+		pattern  string
+		match    bool
+	}{
+		{"github.com/acme/app/db.(*Store).Query", "github.com/acme/app/db", true},
+		{"github.com/acme/app/db/sql.Open.func1", "github.com/acme/app/db/*", true},
+		{"github.com/acme/app/db.Open", "github.com/acme/app/db/*", false},
+		{"github.com/acme/app/db.Open", "github.com/acme/app/db/...", true},
+		{"github.com/acme/app/db/sql/pg.Open", "github.com/acme/app/db/...", true},
+		{"github.com/acme/app/net.Dial", "net", true},
+		{"net.Dial", "net", true},
+		{"main.main", "net", false},
+	} {
+		if match := matchPackage(tc.pattern, callerPackage(tc.function)); match != tc.match {
+			t.Errorf("%v on %v: expected %v, have %v", tc.pattern, tc.function, tc.match, match)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package logman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var errWritersClosed = errors.New("writers are closed")
+
+// destination is an opened writer of Logger.
+// write receives formatted line of message processed on level.
+type destination interface {
+	write(app, level string, message Message, text []byte) error
+	sync() error
+	close() error
+}
+
+// writerRegistry opens every destination once and keeps it for all levels and messages of Logger.
+type writerRegistry struct {
+	mu           sync.RWMutex
+	destinations map[string]destination
+	segments     segmentSettings
+	closed       bool
+}
+
+func newWriterRegistry(segments segmentSettings) *writerRegistry {
+	return &writerRegistry{
+		destinations: make(map[string]destination),
+		segments:     segments,
+	}
+}
+
+// get returns destination for writer key, opening it on first request.
+func (r *writerRegistry) get(writerKey string) (destination, error) {
+	r.mu.RLock()
+	dest, ok := r.destinations[writerKey]
+	closed := r.closed
+	r.mu.RUnlock()
+	if closed {
+		return nil, errWritersClosed
+	}
+	if ok {
+		return dest, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, errWritersClosed
+	}
+	if dest, ok := r.destinations[writerKey]; ok {
+		return dest, nil
+	}
+	dest, err := openDestination(writerKey, r.segments)
+	if err != nil {
+		return nil, err
+	}
+	r.destinations[writerKey] = dest
+	return dest, nil
+}
+
+// Sync commits content of all opened destinations to stable storage.
+func (r *writerRegistry) Sync() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	errorStack := []error{}
+	for key, dest := range r.destinations {
+		if err := dest.sync(); err != nil {
+			errorStack = append(errorStack, fmt.Errorf("writer '%v': %v", key, err))
+		}
+	}
+	return joinErrors("sync failed", errorStack...)
+}
+
+// Close closes all opened destinations. Writing after Close returns error.
+func (r *writerRegistry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	errorStack := []error{}
+	for key, dest := range r.destinations {
+		if err := dest.close(); err != nil {
+			errorStack = append(errorStack, fmt.Errorf("writer '%v': %v", key, err))
+		}
+	}
+	r.destinations = make(map[string]destination)
+	return joinErrors("close failed", errorStack...)
+}
+
+// register adds destination under name. Writer key equal to name will write to it.
+func (r *writerRegistry) register(name string, dest destination) error {
+	switch name {
+	case Stdout, Stderr, "":
+		return fmt.Errorf("writer name '%v' is reserved", name)
+	}
+	if dest == nil {
+		return fmt.Errorf("writer '%v' is nil", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errWritersClosed
+	}
+	if old, ok := r.destinations[name]; ok {
+		if err := old.close(); err != nil {
+			return fmt.Errorf("failed to close replaced writer '%v': %v", name, err)
+		}
+	}
+	r.destinations[name] = dest
+	return nil
+}
+
+// openDestination opens writer key as console, file or directory.
+// Missing path is created: key ending with path separator becomes directory,
+// any other key becomes file (with all parent directories).
+func openDestination(writerKey string, segments segmentSettings) (destination, error) {
+	switch writerKey {
+	case Stdout:
+		return stdoutDestination, nil
+	case Stderr:
+		return stderrDestination, nil
+	}
+	switch writerInfo(writerKey) {
+	case "file":
+		return openFileDestination(writerKey)
+	case "dir":
+		return newDirDestination(writerKey, segments), nil
+	case "missing":
+		if strings.HasSuffix(writerKey, string(filepath.Separator)) || strings.HasSuffix(writerKey, "/") {
+			if err := os.MkdirAll(writerKey, dirPerm); err != nil {
+				return nil, fmt.Errorf("failed to create writer directory '%v': %v", writerKey, err)
+			}
+			return newDirDestination(writerKey, segments), nil
+		}
+		if err := os.MkdirAll(filepath.Dir(writerKey), dirPerm); err != nil {
+			return nil, fmt.Errorf("failed to create writer directory '%v': %v", filepath.Dir(writerKey), err)
+		}
+		return openFileDestination(writerKey)
+	}
+	return nil, fmt.Errorf("failed to open writer '%v': not a file or directory", writerKey)
+}
+
+func openFileDestination(path string) (destination, error) {
+	f, err := os.OpenFile(path, flags, perm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open writer '%v': %v", path, err)
+	}
+	return &fileDestination{file: f}, nil
+}
+
+// consoleDestination is shared by all loggers. It is never closed.
+type consoleDestination struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+var stdoutDestination = &consoleDestination{file: os.Stdout}
+var stderrDestination = &consoleDestination{file: os.Stderr}
+
+func (c *consoleDestination) write(_, _ string, _ Message, text []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.file.Write(text)
+	return err
+}
+
+func (c *consoleDestination) sync() error {
+	return nil
+}
+
+func (c *consoleDestination) close() error {
+	return nil
+}
+
+// fileDestination appends lines to a file opened once.
+type fileDestination struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (f *fileDestination) write(_, _ string, _ Message, text []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err := f.file.Write(text)
+	return err
+}
+
+func (f *fileDestination) sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Sync()
+}
+
+func (f *fileDestination) close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// streamDestination writes to io.Writer registered by user.
+// Writer is synced and closed if it implements Sync() error or io.Closer.
+type streamDestination struct {
+	mu       sync.Mutex
+	writer   io.Writer
+	identity string // identity of namedWriter it is opened by
+}
+
+func (s *streamDestination) write(_, _ string, _ Message, text []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.writer.Write(text)
+	return err
+}
+
+func (s *streamDestination) sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if syncer, ok := s.writer.(interface{ Sync() error }); ok && !isConsole(s.writer) {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+func (s *streamDestination) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if closer, ok := s.writer.(io.Closer); ok && !isConsole(s.writer) {
+		return closer.Close()
+	}
+	return nil
+}
+
+func isConsole(w io.Writer) bool {
+	return w == os.Stdout || w == os.Stderr
+}
+
+// writerInfo classifies writer key without opening it.
+func writerInfo(path string) string {
+	f, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "missing"
+	}
+	if err != nil {
+		return "bad"
+	}
+	if f.IsDir() {
+		return "dir"
+	}
+	if f.Mode().IsRegular() {
+		return "file"
+	}
+	return "bad"
+}
+
+// RegisterWriter adds io.Writer under name, so name can be used as writer key
+// in SetLevelWriterFormatter. Writer registered with the same name is closed and replaced.
+func (l *Logger) RegisterWriter(name string, w io.Writer) error {
+	if w == nil {
+		return fmt.Errorf("writer '%v' is nil", name)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state.Load().epoch.writers.register(name, &streamDestination{writer: w})
+}
+
+// Sync commits content of file writers opened by Logger to stable storage.
+func (l *Logger) Sync() error {
+	return l.state.Load().epoch.writers.Sync()
+}
+
+// Close reports messages suppressed by samplers, writes messages queued by async Logger
+// (waiting until ctx is done) and repeats counted by deduplication, then closes
+// all writers opened by Logger and registered writers implementing io.Closer.
+// Messages processed after Close are reported as errors.
+func (l *Logger) Close(ctx context.Context) error {
+	emitSummaries(l, haltSamplers(l.state.Load().samplers))
+	if l.async != nil {
+		if err := l.async.close(ctx); err != nil {
+			return fmt.Errorf("failed to flush async messages: %v", err)
+		}
+	}
+	flushDedups(l, l.state.Load())
+	l.retiring.Wait()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state.Load().epoch.writers.Close()
+}
+
+// Sync commits content of file writers opened by default Logger to stable storage.
+func Sync() error {
+	return Default().Sync()
+}
+
+// Close flushes and closes all writers opened by default Logger.
+// Place it at the end of the program.
+func Close(ctx context.Context) error {
+	return Default().Close(ctx)
+}
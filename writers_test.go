@@ -0,0 +1,82 @@
+package logman
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriterRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.log")
+	if err := os.WriteFile(path, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO), NewLoggingLevel(WARN, LevelImportance(ImportanceWARN))),
+		WithGlobalWriterFormatter(path, formatter),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := lgr.Info("info %v", i); err != nil {
+			t.Fatal(err)
+		}
+		if err := lgr.Warn("warn %v", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(lgr.state.Load().epoch.writers.destinations) != 1 {
+		t.Errorf("expected 1 shared destination, have %v", len(lgr.state.Load().epoch.writers.destinations))
+	}
+	if err := lgr.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := lgr.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	bt, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(bt), "\n"); lines != 200 {
+		t.Errorf("expected 200 lines, have %v", lines)
+	}
+	if err := lgr.Info("after close"); err == nil {
+		t.Errorf("expected error writing after Close")
+	}
+}
+
+func TestNamedWritersAndMissingPaths(t *testing.T) {
+	dir := t.TempDir()
+	buf := &bytes.Buffer{}
+	nested := filepath.Join(dir, "nested", "deeper", "app.log")
+	formatter := NewFormatter(WithRequestedFields(Request_MessageOnly))
+	lgr, err := New(
+		WithLogLevels(NewLoggingLevel(INFO, WithWriter(nested, formatter))),
+		WithWriterNamed("buffer", buf, formatter),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lgr.Info("value=%v", 42); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "value=42 \n" {
+		t.Errorf("buffer: have %q", buf.String())
+	}
+	bt, err := os.ReadFile(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bt) != "value=42 \n" {
+		t.Errorf("created file: have %q", string(bt))
+	}
+	if _, err := New(WithWriterNamed(Stdout, buf, formatter)); err == nil {
+		t.Errorf("expected error registering reserved name")
+	}
+}